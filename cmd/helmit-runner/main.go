@@ -19,28 +19,43 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 )
 
 const readyFile = "/tmp/bin-ready"
 
+// canceledExitCode is the exit code when the runner is terminated before the binary is started
+const canceledExitCode = 130
+
 func main() {
-	awaitReady()
-	if err := run(); err != nil {
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	awaitReady(signalCh)
+	if err := run(signalCh); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+			os.Exit(exitErr.ExitCode())
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
 // awaitReady waits for the job to become ready
-func awaitReady() {
+// If the runner is terminated before the job becomes ready, it exits immediately.
+func awaitReady(signalCh <-chan os.Signal) {
 	for {
 		if isReady() {
 			return
 		}
-		time.Sleep(time.Second)
+		select {
+		case <-time.After(time.Second):
+		case <-signalCh:
+			os.Exit(canceledExitCode)
+		}
 	}
 }
 
@@ -65,7 +80,8 @@ func getBinaryFile() (string, error) {
 }
 
 // run runs the main
-func run() error {
+// Signals received by the runner are forwarded to the binary to allow it to shut down gracefully.
+func run(signalCh <-chan os.Signal) error {
 	fileName, err := getBinaryFile()
 	if err != nil {
 		return err
@@ -78,5 +94,21 @@ func run() error {
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 	cmd.Env = os.Environ()
-	return cmd.Run()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		for {
+			select {
+			case sig := <-signalCh:
+				_ = cmd.Process.Signal(sig)
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+	return cmd.Wait()
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/onosproject/helmit/pkg/benchmark"
 	"github.com/onosproject/helmit/pkg/helm"
 	"github.com/onosproject/helmit/pkg/input"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"time"
 )
 
@@ -33,6 +34,37 @@ type AtomixBenchmarkSuite struct {
 	m _map.Map
 }
 
+// PolicyRules returns the RBAC rules required to deploy the Atomix charts
+func (s *AtomixBenchmarkSuite) PolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services", "serviceaccounts"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apiextensions.k8s.io"},
+			Resources: []string{"customresourcedefinitions"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"cloud.atomix.io", "k8s.atomix.io"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+	}
+}
+
 // SetupBenchmarkSuite sets up the Atomix cluster
 func (s *AtomixBenchmarkSuite) SetupSuite(c *benchmark.Context) error {
 	err := helm.Chart("atomix-controller").
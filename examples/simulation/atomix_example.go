@@ -23,6 +23,7 @@ import (
 	"github.com/onosproject/helmit/pkg/input"
 	"github.com/onosproject/helmit/pkg/simulation"
 	"github.com/onosproject/helmit/pkg/test"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"time"
 )
 
@@ -35,6 +36,37 @@ type AtomixSimulationSuite struct {
 	m _map.Map
 }
 
+// PolicyRules returns the RBAC rules required to deploy the Atomix charts
+func (s *AtomixSimulationSuite) PolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services", "serviceaccounts"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apiextensions.k8s.io"},
+			Resources: []string{"customresourcedefinitions"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"cloud.atomix.io", "k8s.atomix.io"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+	}
+}
+
 // ScheduleSimulator schedules simulator functions
 func (s *AtomixSimulationSuite) ScheduleSimulator(sim *simulation.Simulator) {
 	sim.Schedule("get", s.SimulateMapGet, 1*time.Second, 1)
@@ -22,6 +22,7 @@ import (
 	"github.com/onosproject/helmit/pkg/helm"
 	"github.com/onosproject/helmit/pkg/test"
 	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"testing"
 )
 
@@ -30,6 +31,37 @@ type AtomixTestSuite struct {
 	test.Suite
 }
 
+// PolicyRules returns the RBAC rules required to deploy the Atomix charts
+func (s *AtomixTestSuite) PolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"services", "serviceaccounts"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"apiextensions.k8s.io"},
+			Resources: []string{"customresourcedefinitions"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"cloud.atomix.io", "k8s.atomix.io"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+	}
+}
+
 // SetupTestSuite sets up the Atomix cluster
 func (s *AtomixTestSuite) SetupTestSuite() error {
 	err := helm.Chart("atomix-controller").
@@ -15,6 +15,7 @@
 package benchmark
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"reflect"
@@ -134,7 +135,8 @@ type Benchmark struct {
 }
 
 // Run runs the benchmark with the given parameters
-func (b *Benchmark) run(suite BenchmarkingSuite) (*RunResponse, error) {
+// If the context is canceled, the benchmark is stopped and the context error is returned.
+func (b *Benchmark) run(ctx context.Context, suite BenchmarkingSuite) (*RunResponse, error) {
 	var f func() error
 	methods := reflect.TypeOf(suite)
 	if method, ok := methods.MethodByName(b.Name); ok {
@@ -152,10 +154,13 @@ func (b *Benchmark) run(suite BenchmarkingSuite) (*RunResponse, error) {
 	}
 
 	// Warm the benchmark
-	b.warmRequests(f)
+	b.warmRequests(ctx, f)
 
 	// Run the benchmark
-	requests, runTime, results := b.runRequests(f)
+	requests, runTime, results := b.runRequests(ctx, f)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Calculate the total latency from latency results
 	var totalLatency time.Duration
@@ -182,7 +187,7 @@ func (b *Benchmark) run(suite BenchmarkingSuite) (*RunResponse, error) {
 }
 
 // warm warms up the benchmark
-func (b *Benchmark) warmRequests(f func() error) {
+func (b *Benchmark) warmRequests(ctx context.Context, f func() error) {
 	// Create an iteration channel and wait group and create a goroutine for each client
 	wg := &sync.WaitGroup{}
 	requestCh := make(chan struct{}, b.parallelism)
@@ -198,7 +203,7 @@ func (b *Benchmark) warmRequests(f func() error) {
 
 	// Run for the warm up duration to prepare the benchmark
 	start := time.Now()
-	for time.Since(start) < warmUpDuration {
+	for time.Since(start) < warmUpDuration && ctx.Err() == nil {
 		requestCh <- struct{}{}
 	}
 	close(requestCh)
@@ -208,7 +213,7 @@ func (b *Benchmark) warmRequests(f func() error) {
 }
 
 // run runs the benchmark
-func (b *Benchmark) runRequests(f func() error) (int, time.Duration, []time.Duration) {
+func (b *Benchmark) runRequests(ctx context.Context, f func() error) (int, time.Duration, []time.Duration) {
 	// Create an iteration channel and wait group and create a goroutine for each client
 	wg := &sync.WaitGroup{}
 	requestCh := make(chan struct{}, b.parallelism)
@@ -270,7 +275,7 @@ func (b *Benchmark) runRequests(f func() error) (int, time.Duration, []time.Dura
 
 	// Iterate through the request count or until the time duration has been met
 	requests := 0
-	for (b.requests == 0 || requests < b.requests) && (b.duration == nil || time.Since(start) < *b.duration) {
+	for (b.requests == 0 || requests < b.requests) && (b.duration == nil || time.Since(start) < *b.duration) && ctx.Err() == nil {
 		requestCh <- struct{}{}
 		requests++
 	}
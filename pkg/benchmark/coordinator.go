@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 	"math"
 	"os"
+	"path/filepath"
 	"sync"
 	"text/tabwriter"
 	"time"
@@ -44,6 +45,11 @@ type Coordinator struct {
 
 // Run runs the tests
 func (c *Coordinator) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := job.OnCancel(cancel)
+	defer stop()
+
 	var suites []string
 	if c.config.Suite == "" {
 		suites = registry.GetBenchmarkSuites()
@@ -63,8 +69,15 @@ func (c *Coordinator) Run() error {
 				Context:         c.config.Config.Context,
 				Values:          c.config.Config.Values,
 				ValueFiles:      c.config.Config.ValueFiles,
+				StrictValues:    c.config.Config.StrictValues,
+				HelmDriver:      c.config.Config.HelmDriver,
 				Env:             c.config.Config.Env,
 				Timeout:         c.config.Config.Timeout,
+				PodTemplate:     c.config.Config.PodTemplate,
+				NoTeardown:      c.config.Config.NoTeardown,
+				KeepOnFailure:   c.config.Config.KeepOnFailure,
+				ArtifactsDir:    c.config.Config.ArtifactsDir,
+				RBAC:            c.config.Config.RBAC.WithSuite(registry.GetBenchmarkSuite(suite)),
 			},
 			Suite:       suite,
 			Benchmark:   c.config.Benchmark,
@@ -76,16 +89,21 @@ func (c *Coordinator) Run() error {
 			Args:        c.config.Args,
 		}
 		worker := &WorkerTask{
-			runner: job.NewNamespace(jobID),
+			runner: job.NewNamespace(jobID, config.RBAC),
 			config: config,
 		}
 		workers[i] = worker
 	}
-	return runWorkers(workers)
+	err := runWorkers(ctx, workers)
+	job.PrintRetainedNamespaces(os.Stdout)
+	if ctx.Err() != nil {
+		return job.ErrCanceled
+	}
+	return err
 }
 
 // runWorkers runs the given test jobs
-func runWorkers(tasks []*WorkerTask) error {
+func runWorkers(ctx context.Context, tasks []*WorkerTask) error {
 	// Start jobs in separate goroutines
 	wg := &sync.WaitGroup{}
 	errChan := make(chan error, len(tasks))
@@ -93,7 +111,7 @@ func runWorkers(tasks []*WorkerTask) error {
 	for _, task := range tasks {
 		wg.Add(1)
 		go func(task *WorkerTask) {
-			status, err := task.Run()
+			status, err := task.Run(ctx)
 			if err != nil {
 				errChan <- err
 			} else {
@@ -133,32 +151,51 @@ func newJobID(testID, suite string) string {
 type WorkerTask struct {
 	runner  *job.Runner
 	config  *Config
+	jobs    []*job.Job
+	jobsMu  sync.Mutex
 	workers []WorkerServiceClient
 }
 
 // Run runs the worker job
-func (t *WorkerTask) Run() (int, error) {
+// If the context is canceled, the workers are stopped and torn down and the namespace is deleted unless
+// teardown is disabled.
+func (t *WorkerTask) Run(ctx context.Context) (int, error) {
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.runner.Cancel()
+		case <-doneCh:
+		}
+	}()
+
 	// Start the job
-	err := t.run()
-	if err != nil {
-		_ = t.tearDown()
-		return 0, err
+	err := t.run(ctx)
+	close(doneCh)
+	failed := ctx.Err() == nil && err != nil
+	if failed && t.config.ArtifactsDir != "" {
+		_ = t.runner.CaptureDiagnostics(filepath.Join(t.config.ArtifactsDir, t.config.Suite, job.DiagnosticsDir))
+	}
+	if ctx.Err() == nil {
+		t.collectArtifacts()
+	} else {
+		t.releaseArtifacts()
 	}
 
 	// Tear down the cluster if necessary
-	_ = t.tearDown()
-	return 0, nil
+	_ = t.tearDown(failed)
+	return 0, err
 }
 
 // start starts the test job
-func (t *WorkerTask) run() error {
+func (t *WorkerTask) run(ctx context.Context) error {
 	if err := t.runner.CreateNamespace(); err != nil {
 		return err
 	}
 	if err := t.createWorkers(); err != nil {
 		return err
 	}
-	if err := t.runBenchmarks(); err != nil {
+	if err := t.runBenchmarks(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -197,8 +234,15 @@ func (t *WorkerTask) createWorker(worker int) error {
 			Context:         t.config.Config.Context,
 			Values:          t.config.Config.Values,
 			ValueFiles:      t.config.Config.ValueFiles,
+			StrictValues:    t.config.Config.StrictValues,
+			HelmDriver:      t.config.Config.HelmDriver,
 			Env:             env,
 			Timeout:         t.config.Config.Timeout,
+			PodTemplate:     t.config.Config.PodTemplate,
+			NoTeardown:      t.config.Config.NoTeardown,
+			KeepOnFailure:   t.config.Config.KeepOnFailure,
+			ArtifactsDir:    t.config.Config.ArtifactsDir,
+			RBAC:            t.config.Config.RBAC,
 		},
 		JobConfig: &Config{
 			Config: &job.Config{
@@ -209,8 +253,15 @@ func (t *WorkerTask) createWorker(worker int) error {
 				Context:         t.config.Config.Context,
 				Values:          t.config.Config.Values,
 				ValueFiles:      t.config.Config.ValueFiles,
+				StrictValues:    t.config.Config.StrictValues,
+				HelmDriver:      t.config.Config.HelmDriver,
 				Env:             env,
 				Timeout:         t.config.Config.Timeout,
+				PodTemplate:     t.config.Config.PodTemplate,
+				NoTeardown:      t.config.Config.NoTeardown,
+				KeepOnFailure:   t.config.Config.KeepOnFailure,
+				ArtifactsDir:    t.config.Config.ArtifactsDir,
+				RBAC:            t.config.Config.RBAC,
 			},
 			Suite:       t.config.Suite,
 			Benchmark:   t.config.Benchmark,
@@ -223,6 +274,9 @@ func (t *WorkerTask) createWorker(worker int) error {
 		},
 		Type: benchmarkJobType,
 	}
+	t.jobsMu.Lock()
+	t.jobs = append(t.jobs, job)
+	t.jobsMu.Unlock()
 	return t.runner.StartJob(job)
 }
 
@@ -245,14 +299,14 @@ func (t *WorkerTask) getWorkers() ([]WorkerServiceClient, error) {
 }
 
 // setupSuite sets up the benchmark suite
-func (t *WorkerTask) setupSuite() error {
+func (t *WorkerTask) setupSuite(ctx context.Context) error {
 	workers, err := t.getWorkers()
 	if err != nil {
 		return err
 	}
 
 	worker := workers[0]
-	_, err = worker.SetupSuite(context.Background(), &SuiteRequest{
+	_, err = worker.SetupSuite(ctx, &SuiteRequest{
 		Suite: t.config.Suite,
 		Args:  t.config.Args,
 	})
@@ -260,7 +314,7 @@ func (t *WorkerTask) setupSuite() error {
 }
 
 // setupWorkers sets up the benchmark workers
-func (t *WorkerTask) setupWorkers() error {
+func (t *WorkerTask) setupWorkers(ctx context.Context) error {
 	workers, err := t.getWorkers()
 	if err != nil {
 		return err
@@ -271,7 +325,7 @@ func (t *WorkerTask) setupWorkers() error {
 	for _, worker := range workers {
 		wg.Add(1)
 		go func(worker WorkerServiceClient) {
-			_, err = worker.SetupWorker(context.Background(), &SuiteRequest{
+			_, err = worker.SetupWorker(ctx, &SuiteRequest{
 				Suite: t.config.Suite,
 				Args:  t.config.Args,
 			})
@@ -291,7 +345,7 @@ func (t *WorkerTask) setupWorkers() error {
 }
 
 // setupBenchmark sets up the given benchmark
-func (t *WorkerTask) setupBenchmark(benchmark string) error {
+func (t *WorkerTask) setupBenchmark(ctx context.Context, benchmark string) error {
 	workers, err := t.getWorkers()
 	if err != nil {
 		return err
@@ -302,7 +356,7 @@ func (t *WorkerTask) setupBenchmark(benchmark string) error {
 	for _, worker := range workers {
 		wg.Add(1)
 		go func(worker WorkerServiceClient) {
-			_, err = worker.SetupBenchmark(context.Background(), &BenchmarkRequest{
+			_, err = worker.SetupBenchmark(ctx, &BenchmarkRequest{
 				Suite:     t.config.Suite,
 				Benchmark: benchmark,
 				Args:      t.config.Args,
@@ -323,23 +377,25 @@ func (t *WorkerTask) setupBenchmark(benchmark string) error {
 }
 
 // runBenchmarks runs the given benchmarks
-func (t *WorkerTask) runBenchmarks() error {
+func (t *WorkerTask) runBenchmarks(ctx context.Context) error {
 	// Setup the benchmark suite on one of the workers
-	if err := t.setupSuite(); err != nil {
+	if err := t.setupSuite(ctx); err != nil {
 		return err
 	}
+	defer t.tearDownSuite()
 
 	// Setup the workers
-	if err := t.setupWorkers(); err != nil {
+	if err := t.setupWorkers(ctx); err != nil {
 		return err
 	}
+	defer t.tearDownWorkers()
 
 	// Run the benchmarks
 	results := make([]result, 0)
 	if t.config.Benchmark != "" {
 		step := logging.NewStep(t.config.ID, "Run benchmark %s", t.config.Benchmark)
 		step.Start()
-		result, err := t.runBenchmark(t.config.Benchmark)
+		result, err := t.runBenchmark(ctx, t.config.Benchmark)
 		if err != nil {
 			step.Fail(err)
 			return err
@@ -354,7 +410,7 @@ func (t *WorkerTask) runBenchmarks() error {
 		for _, benchmark := range benchmarks {
 			benchmarkSuite := logging.NewStep(t.config.ID, "Run benchmark %s", benchmark)
 			benchmarkSuite.Start()
-			result, err := t.runBenchmark(benchmark)
+			result, err := t.runBenchmark(ctx, benchmark)
 			if err != nil {
 				benchmarkSuite.Fail(err)
 				suiteStep.Fail(err)
@@ -387,11 +443,12 @@ func (t *WorkerTask) runBenchmarks() error {
 }
 
 // runBenchmark runs the given benchmark
-func (t *WorkerTask) runBenchmark(benchmark string) (result, error) {
+func (t *WorkerTask) runBenchmark(ctx context.Context, benchmark string) (result, error) {
 	// Setup the benchmark
-	if err := t.setupBenchmark(benchmark); err != nil {
+	if err := t.setupBenchmark(ctx, benchmark); err != nil {
 		return result{}, err
 	}
+	defer t.tearDownBenchmark(benchmark)
 
 	workers, err := t.getWorkers()
 	if err != nil {
@@ -405,7 +462,7 @@ func (t *WorkerTask) runBenchmark(benchmark string) (result, error) {
 	for _, worker := range workers {
 		wg.Add(1)
 		go func(worker WorkerServiceClient, requests int, duration *time.Duration) {
-			result, err := worker.RunBenchmark(context.Background(), &RunRequest{
+			result, err := worker.RunBenchmark(ctx, &RunRequest{
 				Suite:       t.config.Suite,
 				Benchmark:   benchmark,
 				Requests:    uint32(requests),
@@ -475,7 +532,86 @@ type result struct {
 	latencyPercentiles map[float32]time.Duration
 }
 
+// tearDownTimeout is the time allowed for each worker tear down hook to complete
+const tearDownTimeout = time.Minute
+
+// tearDownBenchmark tears down the given benchmark on all workers
+func (t *WorkerTask) tearDownBenchmark(benchmark string) {
+	workers, err := t.getWorkers()
+	if err != nil {
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(worker WorkerServiceClient) {
+			ctx, cancel := context.WithTimeout(context.Background(), tearDownTimeout)
+			defer cancel()
+			_, _ = worker.TearDownBenchmark(ctx, &BenchmarkRequest{
+				Suite:     t.config.Suite,
+				Benchmark: benchmark,
+				Args:      t.config.Args,
+			})
+			wg.Done()
+		}(worker)
+	}
+	wg.Wait()
+}
+
+// tearDownWorkers tears down the benchmark workers
+func (t *WorkerTask) tearDownWorkers() {
+	workers, err := t.getWorkers()
+	if err != nil {
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, worker := range workers {
+		wg.Add(1)
+		go func(worker WorkerServiceClient) {
+			ctx, cancel := context.WithTimeout(context.Background(), tearDownTimeout)
+			defer cancel()
+			_, _ = worker.TearDownWorker(ctx, &SuiteRequest{
+				Suite: t.config.Suite,
+				Args:  t.config.Args,
+			})
+			wg.Done()
+		}(worker)
+	}
+	wg.Wait()
+}
+
+// tearDownSuite tears down the benchmark suite
+func (t *WorkerTask) tearDownSuite() {
+	workers, err := t.getWorkers()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tearDownTimeout)
+	defer cancel()
+	_, _ = workers[0].TearDownSuite(ctx, &SuiteRequest{
+		Suite: t.config.Suite,
+		Args:  t.config.Args,
+	})
+}
+
+// collectArtifacts collects the artifacts written by each worker
+func (t *WorkerTask) collectArtifacts() {
+	for _, job := range t.jobs {
+		_ = t.runner.CollectArtifacts(job, filepath.Join(t.config.ArtifactsDir, t.config.Suite, job.ID))
+	}
+}
+
+// releaseArtifacts stops the artifacts container of each worker without collecting its artifacts
+func (t *WorkerTask) releaseArtifacts() {
+	for _, job := range t.jobs {
+		_ = t.runner.ReleaseArtifacts(job)
+	}
+}
+
 // tearDown tears down the job
-func (t *WorkerTask) tearDown() error {
-	return t.runner.DeleteNamespace()
+func (t *WorkerTask) tearDown(failed bool) error {
+	return t.runner.TearDown(t.config.Config, failed)
 }
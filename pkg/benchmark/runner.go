@@ -46,6 +46,14 @@ func Run(config *Config) error {
 		configContext = path.Base(config.Context)
 	}
 
+	// The coordinator collects artifacts into its own artifacts directory to be copied back to the client
+	// If no artifacts directory is configured, artifacts are copied to a default local directory so that
+	// diagnostics for failed jobs are always available.
+	if config.ArtifactsDir == "" {
+		config.ArtifactsDir = jobs.DefaultArtifactsDir()
+	}
+	configArtifactsDir := jobs.ArtifactsPath
+
 	job := &jobs.Job{
 		Config: config.Config,
 		JobConfig: &Config{
@@ -57,9 +65,16 @@ func Run(config *Config) error {
 				Context:         configContext,
 				Values:          config.Values,
 				ValueFiles:      configValueFiles,
+				StrictValues:    config.StrictValues,
+				HelmDriver:      config.HelmDriver,
 				Args:            config.Config.Args,
 				Env:             config.Env,
 				Timeout:         config.Timeout,
+				PodTemplate:     config.PodTemplate,
+				NoTeardown:      config.NoTeardown,
+				KeepOnFailure:   config.KeepOnFailure,
+				ArtifactsDir:    configArtifactsDir,
+				RBAC:            config.RBAC,
 			},
 			Suite:       config.Suite,
 			Benchmark:   config.Benchmark,
@@ -77,7 +92,10 @@ func Run(config *Config) error {
 
 // Main runs a test
 func Main() {
-	if err := run(); err != nil {
+	if err := run(); err == jobs.ErrCanceled {
+		println("Benchmark canceled")
+		os.Exit(jobs.CanceledExitCode)
+	} else if err != nil {
 		println("Benchmark failed " + err.Error())
 		os.Exit(1)
 	}
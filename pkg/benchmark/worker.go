@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/onosproject/helmit/pkg/helm"
+	"github.com/onosproject/helmit/pkg/job"
 	"github.com/onosproject/helmit/pkg/registry"
 	"github.com/onosproject/helmit/pkg/util/logging"
 	"google.golang.org/grpc"
@@ -43,9 +44,11 @@ type Worker struct {
 // Run runs a benchmark
 func (w *Worker) Run() error {
 	err := helm.SetContext(&helm.Context{
-		WorkDir:    w.config.Context,
-		Values:     w.config.Values,
-		ValueFiles: w.config.ValueFiles,
+		WorkDir:      w.config.Context,
+		Values:       w.config.Values,
+		ValueFiles:   w.config.ValueFiles,
+		StrictValues: w.config.StrictValues,
+		Driver:       string(w.config.HelmDriver),
 	})
 	if err != nil {
 		return err
@@ -85,6 +88,7 @@ func (w *Worker) SetupSuite(ctx context.Context, request *SuiteRequest) (*SuiteR
 	if setupSuite, ok := suite.(SetupSuite); ok {
 		if err := setupSuite.SetupSuite(newContext(request.Suite, request.Args)); err != nil {
 			step.Fail(err)
+			job.CaptureReleases()
 			return nil, err
 		}
 	}
@@ -129,6 +133,7 @@ func (w *Worker) SetupWorker(ctx context.Context, request *SuiteRequest) (*Suite
 	if setupWorker, ok := suite.(SetupWorker); ok {
 		if err := setupWorker.SetupWorker(newContext(request.Suite, request.Args)); err != nil {
 			step.Fail(err)
+			job.CaptureReleases()
 			return nil, err
 		}
 	}
@@ -174,6 +179,7 @@ func (w *Worker) SetupBenchmark(ctx context.Context, request *BenchmarkRequest)
 	if setupBenchmark, ok := suite.(SetupBenchmark); ok {
 		if err := setupBenchmark.SetupBenchmark(context); err != nil {
 			step.Fail(err)
+			job.CaptureReleases()
 			return nil, err
 		}
 	}
@@ -228,8 +234,10 @@ func (w *Worker) RunBenchmark(ctx context.Context, request *RunRequest) (*RunRes
 
 	context := newContext(request.Benchmark, request.Args)
 	benchmark := newBenchmark(int(request.Requests), request.Duration, int(request.Parallelism), request.MaxLatency, context)
-	result, err := benchmark.run(suite)
+	result, err := benchmark.run(ctx, suite)
 	if err != nil {
+		step.Fail(err)
+		job.CaptureReleases()
 		return nil, err
 	}
 	step.Complete()
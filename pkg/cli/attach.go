@@ -0,0 +1,54 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/onosproject/helmit/pkg/job"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	attachExample = `
+		# Reconnect to a run, streaming its output and exiting with its exit code
+		helmit attach helmit-test-1234
+
+		# Reconnect to a run and copy its artifacts once it completes
+		helmit attach helmit-test-1234 --artifacts-dir ./artifacts`
+)
+
+func getAttachCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "attach <id>",
+		Short:   "Reconnect to a test, benchmark or simulation run and wait for it to complete",
+		Example: attachExample,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runAttachCommand,
+	}
+	cmd.Flags().String("artifacts-dir", "", "the local directory to which to copy the run's artifacts (defaults to a temporary helmit-artifacts directory)")
+	return cmd
+}
+
+func runAttachCommand(cmd *cobra.Command, args []string) error {
+	setupCommand(cmd)
+	artifactsDir, _ := cmd.Flags().GetString("artifacts-dir")
+	status, err := job.Attach(args[0], artifactsDir)
+	if err != nil {
+		return err
+	}
+	os.Exit(status)
+	return nil
+}
@@ -40,6 +40,8 @@ func getBenchCommand() *cobra.Command {
 	cmd.Flags().String("image-pull-policy", string(corev1.PullIfNotPresent), "the Docker image pull policy")
 	cmd.Flags().StringArrayP("values", "f", []string{}, "release values paths")
 	cmd.Flags().StringArray("set", []string{}, "cluster argument overrides")
+	cmd.Flags().Bool("strict-values", false, "fail to install releases with values that are not defined by their charts rather than warning")
+	cmd.Flags().String("helm-driver", string(job.MemoryDriver), "the storage driver with which to record Helm releases: memory, or secret or configmap to make releases visible to the helm CLI")
 	cmd.Flags().StringP("suite", "s", "", "the benchmark suite to run")
 	cmd.Flags().StringP("benchmark", "b", "", "the name of the benchmark to run")
 	cmd.Flags().IntP("workers", "w", 1, "the number of workers to run")
@@ -50,6 +52,11 @@ func getBenchCommand() *cobra.Command {
 	cmd.Flags().StringToStringP("args", "a", map[string]string{}, "a mapping of named benchmark arguments")
 	cmd.Flags().Duration("timeout", 10*time.Minute, "benchmark timeout")
 	cmd.Flags().Bool("no-teardown", false, "do not tear down clusters following tests")
+	cmd.Flags().Bool("keep-on-failure", false, "do not tear down clusters following failed benchmarks")
+	cmd.Flags().String("artifacts-dir", "", "the directory to which to copy artifacts written by benchmark jobs (defaults to a temporary helmit-artifacts directory)")
+	cmd.Flags().String("pod-template", "", "the path to a pod template to merge into benchmark job pods")
+	cmd.Flags().String("rbac", "", "the path to a Role or ClusterRole defining the rules required by benchmark jobs")
+	cmd.Flags().String("rbac-scope", string(job.ClusterScope), "the scope of the roles granted to benchmark jobs: cluster or namespace")
 	return cmd
 }
 
@@ -70,10 +77,18 @@ func runBenchCommand(cmd *cobra.Command, args []string) error {
 	requests, _ := cmd.Flags().GetInt("requests")
 	files, _ := cmd.Flags().GetStringArray("values")
 	sets, _ := cmd.Flags().GetStringArray("set")
+	strictValues, _ := cmd.Flags().GetBool("strict-values")
+	helmDriverFlag, _ := cmd.Flags().GetString("helm-driver")
 	benchArgs, _ := cmd.Flags().GetStringToString("args")
 	timeout, _ := cmd.Flags().GetDuration("timeout")
 	imagePullPolicy, _ := cmd.Flags().GetString("image-pull-policy")
 	pullPolicy := corev1.PullPolicy(imagePullPolicy)
+	noTeardown, _ := cmd.Flags().GetBool("no-teardown")
+	keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
+	artifactsDir, _ := cmd.Flags().GetString("artifacts-dir")
+	podTemplateFile, _ := cmd.Flags().GetString("pod-template")
+	rbacFile, _ := cmd.Flags().GetString("rbac")
+	rbacScope, _ := cmd.Flags().GetString("rbac-scope")
 
 	// Either a command package or image must be specified
 	if pkgPath == "" && image == "" {
@@ -132,6 +147,21 @@ func runBenchCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	podTemplate, err := parsePodTemplate(podTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	rbac, err := parseRBAC(rbacFile, rbacScope)
+	if err != nil {
+		return err
+	}
+
+	helmDriver, err := parseHelmDriver(helmDriverFlag)
+	if err != nil {
+		return err
+	}
+
 	config := &benchmark.Config{
 		Config: &job.Config{
 			ID:              benchID,
@@ -140,8 +170,15 @@ func runBenchCommand(cmd *cobra.Command, args []string) error {
 			ImagePullPolicy: corev1.PullPolicy(pullPolicy),
 			Context:         context,
 			ValueFiles:      valueFiles,
+			StrictValues:    strictValues,
+			HelmDriver:      helmDriver,
 			Values:          values,
 			Timeout:         timeout,
+			PodTemplate:     podTemplate,
+			NoTeardown:      noTeardown,
+			KeepOnFailure:   keepOnFailure,
+			ArtifactsDir:    artifactsDir,
+			RBAC:            rbac,
 		},
 		Suite:       suite,
 		Benchmark:   benchmarkName,
@@ -0,0 +1,53 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/onosproject/helmit/pkg/job"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupExample = `
+		# List the namespaces and role binding subjects left behind by runs more than a day old
+		helmit cleanup --dry-run
+
+		# Delete namespaces left behind by runs more than an hour old
+		helmit cleanup --older-than 1h`
+)
+
+func getCleanupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cleanup",
+		Aliases: []string{"gc"},
+		Short:   "Delete namespaces and role bindings left behind by runs that were not torn down",
+		Example: cleanupExample,
+		Args:    cobra.NoArgs,
+		RunE:    runCleanupCommand,
+	}
+	cmd.Flags().Duration("older-than", 24*time.Hour, "the minimum age of namespaces to delete")
+	cmd.Flags().Bool("dry-run", false, "list the resources to delete without deleting them")
+	return cmd
+}
+
+func runCleanupCommand(cmd *cobra.Command, args []string) error {
+	setupCommand(cmd)
+	olderThan, _ := cmd.Flags().GetDuration("older-than")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	return job.Cleanup(os.Stdout, olderThan, dryRun)
+}
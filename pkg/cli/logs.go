@@ -0,0 +1,51 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/onosproject/helmit/pkg/job"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsExample = `
+		# Print the logs of a run's coordinator
+		helmit logs helmit-test-1234
+
+		# Stream the logs of each of a run's workers
+		helmit logs helmit-test-1234 --workers --follow`
+)
+
+func getLogsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "logs <id>",
+		Short:   "Print the logs of a test, benchmark or simulation run",
+		Example: logsExample,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runLogsCommand,
+	}
+	cmd.Flags().BoolP("follow", "f", false, "stream the logs until the run completes")
+	cmd.Flags().Bool("workers", false, "print the logs of the run's workers rather than its coordinator")
+	return cmd
+}
+
+func runLogsCommand(cmd *cobra.Command, args []string) error {
+	setupCommand(cmd)
+	follow, _ := cmd.Flags().GetBool("follow")
+	workers, _ := cmd.Flags().GetBool("workers")
+	return job.StreamLogs(os.Stdout, args[0], follow, workers)
+}
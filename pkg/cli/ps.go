@@ -0,0 +1,70 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"github.com/onosproject/helmit/pkg/job"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var (
+	psExample = `
+		# List active test, benchmark and simulation runs
+		helmit ps
+
+		# List all runs, including completed runs
+		helmit ps --all`
+)
+
+func getPsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ps",
+		Short:   "List test, benchmark and simulation runs",
+		Example: psExample,
+		Args:    cobra.NoArgs,
+		RunE:    runPsCommand,
+	}
+	cmd.Flags().BoolP("all", "a", false, "list completed runs as well as active runs")
+	return cmd
+}
+
+func runPsCommand(cmd *cobra.Command, args []string) error {
+	setupCommand(cmd)
+	all, _ := cmd.Flags().GetBool("all")
+	runs, err := job.ListRuns(all)
+	if err != nil {
+		return err
+	}
+
+	writer := new(tabwriter.Writer)
+	writer.Init(os.Stdout, 0, 0, 3, ' ', tabwriter.FilterHTML)
+	fmt.Fprintln(writer, "ID\tTYPE\tSTATUS\tAGE\tNAMESPACES")
+	for _, run := range runs {
+		namespaces := strings.Join(run.Namespaces, ",")
+		if namespaces == "" {
+			namespaces = "<none>"
+		}
+		age := duration.HumanDuration(time.Since(run.Created))
+		fmt.Fprintln(writer, fmt.Sprintf("%s\t%s\t%s\t%s\t%s", run.ID, run.Type, run.Status, age, namespaces))
+	}
+	return writer.Flush()
+}
@@ -39,6 +39,10 @@ func GetRootCommand() *cobra.Command {
 	cmd.AddCommand(getTestCommand())
 	cmd.AddCommand(getBenchCommand())
 	cmd.AddCommand(getSimulateCommand())
+	cmd.AddCommand(getCleanupCommand())
+	cmd.AddCommand(getPsCommand())
+	cmd.AddCommand(getLogsCommand())
+	cmd.AddCommand(getAttachCommand())
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 	return cmd
 }
@@ -57,11 +57,19 @@ func getSimulateCommand() *cobra.Command {
 	cmd.Flags().String("image-pull-policy", string(corev1.PullIfNotPresent), "the Docker image pull policy")
 	cmd.Flags().StringArrayP("values", "f", []string{}, "release values paths")
 	cmd.Flags().StringArray("set", []string{}, "cluster argument overrides")
+	cmd.Flags().Bool("strict-values", false, "fail to install releases with values that are not defined by their charts rather than warning")
+	cmd.Flags().String("helm-driver", string(job.MemoryDriver), "the storage driver with which to record Helm releases: memory, or secret or configmap to make releases visible to the helm CLI")
 	cmd.Flags().StringP("simulation", "s", "", "the simulation to run")
 	cmd.Flags().IntP("simulators", "w", 1, "the number of simulator workers to run")
 	cmd.Flags().DurationP("duration", "d", 10*time.Minute, "the duration for which to run the simulation")
 	cmd.Flags().StringToStringP("args", "a", map[string]string{}, "a mapping of named simulation arguments")
 	cmd.Flags().StringToStringP("schedule", "r", map[string]string{}, "a mapping of operations to schedule")
+	cmd.Flags().Bool("no-teardown", false, "do not tear down clusters following simulations")
+	cmd.Flags().Bool("keep-on-failure", false, "do not tear down clusters following failed simulations")
+	cmd.Flags().String("artifacts-dir", "", "the directory to which to copy artifacts written by simulation jobs (defaults to a temporary helmit-artifacts directory)")
+	cmd.Flags().String("pod-template", "", "the path to a pod template to merge into simulation job pods")
+	cmd.Flags().String("rbac", "", "the path to a Role or ClusterRole defining the rules required by simulation jobs")
+	cmd.Flags().String("rbac-scope", string(job.ClusterScope), "the scope of the roles granted to simulation jobs: cluster or namespace")
 	return cmd
 }
 
@@ -81,10 +89,18 @@ func runSimulateCommand(cmd *cobra.Command, args []string) error {
 	timeout, _ := cmd.Flags().GetDuration("timeout")
 	files, _ := cmd.Flags().GetStringArray("values")
 	sets, _ := cmd.Flags().GetStringArray("set")
+	strictValues, _ := cmd.Flags().GetBool("strict-values")
+	helmDriverFlag, _ := cmd.Flags().GetString("helm-driver")
 	simArgs, _ := cmd.Flags().GetStringToString("args")
 	operations, _ := cmd.Flags().GetStringToString("schedule")
 	imagePullPolicy, _ := cmd.Flags().GetString("image-pull-policy")
 	pullPolicy := corev1.PullPolicy(imagePullPolicy)
+	noTeardown, _ := cmd.Flags().GetBool("no-teardown")
+	keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
+	artifactsDir, _ := cmd.Flags().GetString("artifacts-dir")
+	podTemplateFile, _ := cmd.Flags().GetString("pod-template")
+	rbacFile, _ := cmd.Flags().GetString("rbac")
+	rbacScope, _ := cmd.Flags().GetString("rbac-scope")
 
 	// Either a command package or image must be specified
 	if pkgPath == "" && image == "" {
@@ -152,6 +168,21 @@ func runSimulateCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	podTemplate, err := parsePodTemplate(podTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	rbac, err := parseRBAC(rbacFile, rbacScope)
+	if err != nil {
+		return err
+	}
+
+	helmDriver, err := parseHelmDriver(helmDriverFlag)
+	if err != nil {
+		return err
+	}
+
 	config := &simulation.Config{
 		Config: &job.Config{
 			ID:              simID,
@@ -160,8 +191,15 @@ func runSimulateCommand(cmd *cobra.Command, args []string) error {
 			Executable:      executable,
 			Context:         context,
 			ValueFiles:      valueFiles,
+			StrictValues:    strictValues,
+			HelmDriver:      helmDriver,
 			Values:          values,
 			Timeout:         timeout,
+			PodTemplate:     podTemplate,
+			NoTeardown:      noTeardown,
+			KeepOnFailure:   keepOnFailure,
+			ArtifactsDir:    artifactsDir,
+			RBAC:            rbac,
 		},
 		Simulation: sim,
 		Simulators: workers,
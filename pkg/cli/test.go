@@ -17,12 +17,15 @@ package cli
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"github.com/onosproject/helmit/pkg/job"
 	"go/build"
 	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +35,8 @@ import (
 	"github.com/onosproject/helmit/pkg/util/random"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 func init() {
@@ -51,12 +56,31 @@ func getTestCommand() *cobra.Command {
 	cmd.Flags().String("image-pull-policy", string(corev1.PullIfNotPresent), "the Docker image pull policy")
 	cmd.Flags().StringArrayP("values", "f", []string{}, "release values paths")
 	cmd.Flags().StringArray("set", []string{}, "chart value overrides")
-	cmd.Flags().StringSliceP("suite", "s", []string{}, "the name of test suite to run")
-	cmd.Flags().StringSliceP("test", "t", []string{}, "the name of the test method to run")
+	cmd.Flags().Bool("strict-values", false, "fail to install releases with values that are not defined by their charts rather than warning")
+	cmd.Flags().String("helm-driver", string(job.MemoryDriver), "the storage driver with which to record Helm releases: memory, or secret or configmap to make releases visible to the helm CLI")
+	cmd.Flags().StringArrayP("suite", "s", []string{}, "a regular expression selecting the test suites to run")
+	cmd.Flags().StringArrayP("test", "t", []string{}, "a regular expression selecting the test methods to run")
+	cmd.Flags().StringSlice("tags", []string{}, "run only test suites with any of the given tags")
+	cmd.Flags().StringSlice("skip-tags", []string{}, "skip test suites with any of the given tags")
 	cmd.Flags().Duration("timeout", 10*time.Minute, "test timeout")
+	cmd.Flags().Duration("suite-timeout", 0, "the time allowed for all the tests in a suite to run; tests still running when it expires fail with a goroutine dump")
+	cmd.Flags().Duration("test-timeout", 0, "the time allowed for each test to run; tests still running when it expires fail with a goroutine dump")
 	cmd.Flags().Int("iterations", 1, "number of iterations")
 	cmd.Flags().Bool("until-failure", false, "run until an error is detected")
+	cmd.Flags().Int("workers", 0, "the number of worker namespaces across which to shard test suites; by default each suite runs in its own namespace")
+	cmd.Flags().String("durations", "", "the path to the results.json of a previous run with which to balance suites across workers")
+	cmd.Flags().StringArray("matrix", []string{}, "run each suite against each matrix entry, given as {name}={file} where the file contains values keyed by release, or as the path to a matrix file with values keyed by entry name")
+	cmd.Flags().String("shuffle", "off", "randomize the order of suites and tests: off, on, or the seed with which to reproduce a previous order")
+	cmd.Flags().Lookup("shuffle").NoOptDefVal = "on"
+	cmd.Flags().Int("retries", 0, "the number of times to rerun failed test methods; tests that pass when retried are reported as flaky")
+	cmd.Flags().Bool("retry-same-namespace", false, "rerun failed test methods in the namespace in which they failed rather than a fresh namespace, deleting the releases and objects left by the failed attempt first")
 	cmd.Flags().Bool("no-teardown", false, "do not tear down clusters following tests")
+	cmd.Flags().Bool("keep-on-failure", false, "do not tear down clusters following failed tests")
+	cmd.Flags().String("artifacts-dir", "", "the directory to which to copy artifacts written by test jobs (defaults to a temporary helmit-artifacts directory)")
+	cmd.Flags().StringArray("report", []string{}, "write a report of the test results in the format {format}={path}, where format is junit or json")
+	cmd.Flags().String("pod-template", "", "the path to a pod template to merge into test job pods")
+	cmd.Flags().String("rbac", "", "the path to a Role or ClusterRole defining the rules required by test jobs")
+	cmd.Flags().String("rbac-scope", string(job.ClusterScope), "the scope of the roles granted to test jobs: cluster or namespace")
 	return cmd
 }
 
@@ -72,18 +96,41 @@ func runTestCommand(cmd *cobra.Command, args []string) error {
 	image, _ := cmd.Flags().GetString("image")
 	files, _ := cmd.Flags().GetStringArray("values")
 	sets, _ := cmd.Flags().GetStringArray("set")
-	suites, _ := cmd.Flags().GetStringSlice("suite")
-	testNames, _ := cmd.Flags().GetStringSlice("test")
+	strictValues, _ := cmd.Flags().GetBool("strict-values")
+	helmDriverFlag, _ := cmd.Flags().GetString("helm-driver")
+	suites, _ := cmd.Flags().GetStringArray("suite")
+	testNames, _ := cmd.Flags().GetStringArray("test")
+	tags, _ := cmd.Flags().GetStringSlice("tags")
+	skipTags, _ := cmd.Flags().GetStringSlice("skip-tags")
 	timeout, _ := cmd.Flags().GetDuration("timeout")
+	suiteTimeout, _ := cmd.Flags().GetDuration("suite-timeout")
+	testTimeout, _ := cmd.Flags().GetDuration("test-timeout")
 	pullPolicy, _ := cmd.Flags().GetString("image-pull-policy")
 	iterations, _ := cmd.Flags().GetInt("iterations")
 	untilFailure, _ := cmd.Flags().GetBool("until-failure")
+	workers, _ := cmd.Flags().GetInt("workers")
+	durationsFile, _ := cmd.Flags().GetString("durations")
+	matrixFlags, _ := cmd.Flags().GetStringArray("matrix")
+	shuffleFlag, _ := cmd.Flags().GetString("shuffle")
+	retries, _ := cmd.Flags().GetInt("retries")
+	retrySameNamespace, _ := cmd.Flags().GetBool("retry-same-namespace")
+	noTeardown, _ := cmd.Flags().GetBool("no-teardown")
+	keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
+	artifactsDir, _ := cmd.Flags().GetString("artifacts-dir")
+	reportFlags, _ := cmd.Flags().GetStringArray("report")
+	podTemplateFile, _ := cmd.Flags().GetString("pod-template")
+	rbacFile, _ := cmd.Flags().GetString("rbac")
+	rbacScope, _ := cmd.Flags().GetString("rbac-scope")
 
 	// Either a command package or image must be specified
 	if pkgPath == "" && image == "" {
 		return errors.New("must specify either a test package or --image to run")
 	}
 
+	if err := validatePatterns(append(suites, testNames...)); err != nil {
+		return err
+	}
+
 	// Generate a unique test ID
 	testID := random.NewPetName(2)
 
@@ -125,6 +172,44 @@ func runTestCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	podTemplate, err := parsePodTemplate(podTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	rbac, err := parseRBAC(rbacFile, rbacScope)
+	if err != nil {
+		return err
+	}
+
+	helmDriver, err := parseHelmDriver(helmDriverFlag)
+	if err != nil {
+		return err
+	}
+
+	reports, err := parseReports(reportFlags)
+	if err != nil {
+		return err
+	}
+
+	matrix, err := test.ParseMatrix(matrixFlags)
+	if err != nil {
+		return err
+	}
+
+	shuffle, seed, err := parseShuffle(shuffleFlag)
+	if err != nil {
+		return err
+	}
+
+	var durations map[string]time.Duration
+	if durationsFile != "" {
+		durations, err = test.LoadDurations(durationsFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	config := &test.Config{
 		Config: &job.Config{
 			ID:              testID,
@@ -133,13 +218,32 @@ func runTestCommand(cmd *cobra.Command, args []string) error {
 			Executable:      executable,
 			Context:         context,
 			ValueFiles:      valueFiles,
+			StrictValues:    strictValues,
+			HelmDriver:      helmDriver,
 			Values:          values,
 			Timeout:         timeout,
+			PodTemplate:     podTemplate,
+			NoTeardown:      noTeardown,
+			KeepOnFailure:   keepOnFailure,
+			ArtifactsDir:    artifactsDir,
+			RBAC:            rbac,
 		},
-		Suites:     suites,
-		Tests:      testNames,
-		Iterations: iterations,
-		Verbose:    logging.GetVerbose(),
+		Suites:             suites,
+		Tests:              testNames,
+		Tags:               tags,
+		SkipTags:           skipTags,
+		Iterations:         iterations,
+		Workers:            workers,
+		Durations:          durations,
+		Matrix:             matrix,
+		SuiteTimeout:       suiteTimeout,
+		TestTimeout:        testTimeout,
+		Shuffle:            shuffle,
+		Seed:               seed,
+		Retries:            retries,
+		RetrySameNamespace: retrySameNamespace,
+		Verbose:            logging.GetVerbose(),
+		Reports:            reports,
 	}
 	return test.Run(config)
 }
@@ -220,6 +324,99 @@ func parseFiles(files []string) (map[string][]string, error) {
 	return values, nil
 }
 
+// parseShuffle parses the shuffle flag, returning whether to shuffle and the seed with which to shuffle
+func parseShuffle(shuffle string) (bool, int64, error) {
+	switch shuffle {
+	case "off":
+		return false, 0, nil
+	case "on":
+		return true, time.Now().UnixNano(), nil
+	}
+	seed, err := strconv.ParseInt(shuffle, 10, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid shuffle %s: must be off, on or a seed", shuffle)
+	}
+	return true, seed, nil
+}
+
+func parsePodTemplate(file string) (*corev1.PodTemplateSpec, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	podTemplate := &corev1.PodTemplateSpec{}
+	if err := yaml.NewYAMLOrJSONDecoder(reader, 4096).Decode(podTemplate); err != nil {
+		return nil, fmt.Errorf("invalid pod template %s: %v", file, err)
+	}
+	return podTemplate, nil
+}
+
+func parseRBAC(file string, scope string) (*job.RBAC, error) {
+	rbac := &job.RBAC{
+		Scope: job.RBACScope(scope),
+	}
+	if rbac.Scope != job.ClusterScope && rbac.Scope != job.NamespaceScope {
+		return nil, fmt.Errorf("invalid RBAC scope %s", scope)
+	}
+	if file == "" {
+		return rbac, nil
+	}
+
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	role := &rbacv1.ClusterRole{}
+	if err := yaml.NewYAMLOrJSONDecoder(reader, 4096).Decode(role); err != nil {
+		return nil, fmt.Errorf("invalid RBAC rules %s: %v", file, err)
+	}
+	rbac.Rules = role.Rules
+	return rbac, nil
+}
+
+// parseHelmDriver parses the Helm storage driver flag
+func parseHelmDriver(driver string) (job.HelmDriver, error) {
+	helmDriver := job.HelmDriver(driver)
+	if helmDriver != job.MemoryDriver && helmDriver != job.SecretDriver && helmDriver != job.ConfigMapDriver {
+		return "", fmt.Errorf("invalid Helm driver %s: must be memory, secret or configmap", driver)
+	}
+	return helmDriver, nil
+}
+
+func parseReports(reports []string) ([]test.Report, error) {
+	parsed := make([]test.Report, 0, len(reports))
+	for _, report := range reports {
+		r, err := test.ParseReport(report)
+		if err != nil {
+			return nil, err
+		}
+		path, err := filepath.Abs(r.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.Path = path
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
 func parseOverrides(values []string) (map[string][]string, error) {
 	overrides := make(map[string][]string)
 	for _, set := range values {
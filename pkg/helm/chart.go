@@ -16,7 +16,9 @@ package helm
 
 import (
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
 	"k8s.io/client-go/kubernetes"
+	"sync"
 )
 
 // HelmChartClient is a Helm chart client
@@ -38,7 +40,7 @@ func Charts() []*HelmChart {
 	return Client().Charts()
 }
 
-func newChart(name string, repo []string, namespace string, client *kubernetes.Clientset, config *action.Configuration) *HelmChart {
+func newChart(name string, repo []string, namespace string, client kubernetes.Interface, config *action.Configuration) *HelmChart {
 	repository := ""
 	if len(repo) > 0 {
 		repository = repo[0]
@@ -57,11 +59,12 @@ func newChart(name string, repo []string, namespace string, client *kubernetes.C
 type HelmChart struct {
 	HelmReleaseClient
 	namespace  string
-	client     *kubernetes.Clientset
+	client     kubernetes.Interface
 	config     *action.Configuration
 	name       string
 	repository string
 	releases   map[string]*HelmRelease
+	mu         sync.RWMutex
 }
 
 // Name returns the chart name
@@ -76,6 +79,8 @@ func (c *HelmChart) Repository() string {
 
 // Releases returns a list of releases of the chart
 func (c *HelmChart) Releases() []*HelmRelease {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	releases := make([]*HelmRelease, 0, len(c.releases))
 	for _, release := range c.releases {
 		releases = append(releases, release)
@@ -85,6 +90,8 @@ func (c *HelmChart) Releases() []*HelmRelease {
 
 // Release returns the release with the given name
 func (c *HelmChart) Release(name string) *HelmRelease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	release, ok := c.releases[name]
 	if !ok {
 		release = newRelease(name, c.namespace, c.client, c, c.config)
@@ -92,3 +99,19 @@ func (c *HelmChart) Release(name string) *HelmRelease {
 	}
 	return release
 }
+
+// discoverRelease adds a release of the chart that was installed by another process
+// The release's values are seeded with the values it was installed with, so they're retained when it's upgraded.
+func (c *HelmChart) discoverRelease(rel *release.Release) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.releases[rel.Name]; !ok {
+		release := newRelease(rel.Name, c.namespace, c.client, c, c.config)
+		release.release = rel
+		release.discovered = true
+		if rel.Config != nil {
+			release.values = normalize(rel.Config).(map[string]interface{})
+		}
+		c.releases[rel.Name] = release
+	}
+}
@@ -19,9 +19,14 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"k8s.io/client-go/kubernetes"
 	"log"
+	"sync"
 )
 
 var clients = make(map[string]HelmClient)
+var clientsMu sync.Mutex
+
+// defaultDriver is the storage driver used when the context does not specify one
+const defaultDriver = "memory"
 
 // Namespace returns the Helm namespace
 func Namespace() string {
@@ -35,6 +40,8 @@ func Client() HelmClient {
 
 // getClient returns the client for the given namespace
 func getClient(namespace string) HelmClient {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
 	client, ok := clients[namespace]
 	if !ok {
 		configuration, err := getConfig(namespace)
@@ -52,10 +59,14 @@ func getClient(namespace string) HelmClient {
 	return client
 }
 
-// getConfig gets the Helm configuration for the given namespace
+// getConfig gets the Helm configuration for the given namespace using the context's storage driver
 func getConfig(namespace string) (*action.Configuration, error) {
+	driver := context.Driver
+	if driver == "" {
+		driver = defaultDriver
+	}
 	config := &action.Configuration{}
-	if err := config.Init(settings.RESTClientGetter(), namespace, "memory", log.Printf); err != nil {
+	if err := config.Init(settings.RESTClientGetter(), namespace, driver, log.Printf); err != nil {
 		return nil, err
 	}
 	return config, nil
@@ -70,12 +81,24 @@ type HelmClient interface {
 	Namespace(namespace string) HelmClient
 }
 
+// getClients returns the clients that have been created for each namespace
+func getClients() map[string]HelmClient {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	namespaces := make(map[string]HelmClient, len(clients))
+	for namespace, client := range clients {
+		namespaces[namespace] = client
+	}
+	return namespaces
+}
+
 // helmClient is an implementation of the HelmClient interface
 type helmClient struct {
 	namespace string
-	client    *kubernetes.Clientset
+	client    kubernetes.Interface
 	charts    map[string]*HelmChart
 	config    *action.Configuration
+	mu        sync.RWMutex
 }
 
 func (c *helmClient) Namespace(namespace string) HelmClient {
@@ -84,6 +107,8 @@ func (c *helmClient) Namespace(namespace string) HelmClient {
 
 // Charts returns a list of charts in the cluster
 func (c *helmClient) Charts() []*HelmChart {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	charts := make([]*HelmChart, 0, len(c.charts))
 	for _, chart := range c.charts {
 		charts = append(charts, chart)
@@ -93,6 +118,8 @@ func (c *helmClient) Charts() []*HelmChart {
 
 // HelmChart returns a chart
 func (c *helmClient) Chart(name string, repository ...string) *HelmChart {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	chart, ok := c.charts[name]
 	if !ok {
 		chart = newChart(name, repository, c.namespace, c.client, c.config)
@@ -102,17 +129,29 @@ func (c *helmClient) Chart(name string, repository ...string) *HelmChart {
 }
 
 // Releases returns a list of releases
+// Releases recorded by the storage driver that were installed by other processes are included.
 func (c *helmClient) Releases() []*HelmRelease {
+	c.discoverReleases()
 	releases := make([]*HelmRelease, 0)
-	for _, chart := range c.charts {
+	for _, chart := range c.Charts() {
 		releases = append(releases, chart.Releases()...)
 	}
 	return releases
 }
 
 // Release returns the release with the given name
+// If the release is not known to the client, the releases recorded by the storage driver are discovered.
 func (c *helmClient) Release(name string) *HelmRelease {
-	for _, chart := range c.charts {
+	if release := c.getRelease(name); release != nil {
+		return release
+	}
+	c.discoverReleases()
+	return c.getRelease(name)
+}
+
+// getRelease returns the known release with the given name
+func (c *helmClient) getRelease(name string) *HelmRelease {
+	for _, chart := range c.Charts() {
 		for _, release := range chart.Releases() {
 			if release.Name() == name {
 				return release
@@ -121,3 +160,24 @@ func (c *helmClient) Release(name string) *HelmRelease {
 	}
 	return nil
 }
+
+// discoverReleases adds the releases recorded in the namespace that are not yet known to the client
+// Discovered releases are added to a chart named by the chart's metadata. Since the source from which a discovered
+// release was installed is unknown, it's upgraded with the chart recorded with the release.
+func (c *helmClient) discoverReleases() {
+	list := action.NewList(c.config)
+	list.Deployed = true
+	list.Failed = true
+	list.Pending = true
+	list.SetStateMask()
+	releases, err := list.Run()
+	if err != nil {
+		log.Printf("failed to list releases in %s: %s", c.namespace, err)
+		return
+	}
+	for _, release := range releases {
+		if c.getRelease(release.Name) == nil {
+			c.Chart(release.Chart.Metadata.Name).discoverRelease(release)
+		}
+	}
+}
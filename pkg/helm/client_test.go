@@ -0,0 +1,100 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io/ioutil"
+	"k8s.io/client-go/kubernetes/fake"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestDiscoverReleases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path, err := chartutil.Create("test-chart", dir)
+	assert.NoError(t, err)
+
+	// Share the release storage between two clients as if they were separate processes
+	releases := storage.Init(driver.NewMemory())
+	newClient := func() *helmClient {
+		config := newTestConfig(t)
+		config.Releases = releases
+		return &helmClient{
+			namespace: "test",
+			client:    fake.NewSimpleClientset(),
+			charts:    make(map[string]*HelmChart),
+			config:    config,
+		}
+	}
+
+	installer := newClient()
+	assert.NoError(t, installer.Chart(path).Release("installed").Set("image.tag", "v1").Install(false))
+	assert.Len(t, installer.Releases(), 1)
+
+	client := newClient()
+	assert.Nil(t, client.Release("missing"))
+	discovered := client.Releases()
+	assert.Len(t, discovered, 1)
+	assert.Equal(t, "installed", discovered[0].Name())
+	assert.Equal(t, "test", discovered[0].Namespace())
+	assert.Equal(t, 1, discovered[0].Revision())
+	assert.NotNil(t, client.Chart("test-chart").Release("installed").release)
+	assert.Same(t, discovered[0], client.Release("installed"))
+
+	// Discovered releases are upgraded with the chart recorded with the release
+	template, err := discovered[0].Set("replicaCount", 3).Template()
+	assert.NoError(t, err)
+	assert.Len(t, template.Kind("Deployment"), 1)
+	assert.NoError(t, discovered[0].Upgrade(false))
+	assert.Equal(t, 2, discovered[0].Revision())
+	assert.Equal(t, 3, discovered[0].release.Config["replicaCount"])
+
+	// Discovered releases retain the values they were installed with when upgraded
+	assert.Equal(t, "v1", discovered[0].Get("image.tag"))
+	assert.Equal(t, map[string]interface{}{"tag": "v1"}, discovered[0].release.Config["image"])
+}
+
+func TestClientConcurrency(t *testing.T) {
+	client := &helmClient{
+		namespace: "test",
+		client:    fake.NewSimpleClientset(),
+		charts:    make(map[string]*HelmChart),
+		config:    newTestConfig(t),
+	}
+
+	// Parallel tests look up charts and releases concurrently
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("release-%d", i%3)
+			release := client.Chart(fmt.Sprintf("chart-%d", i%2)).Release(name)
+			assert.NotNil(t, release)
+			assert.NotNil(t, client.Release(name))
+			client.Releases()
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, client.Charts(), 2)
+}
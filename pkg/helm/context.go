@@ -43,9 +43,11 @@ func SetContext(ctx *Context) error {
 	}
 
 	context = &Context{
-		WorkDir:    ctxWorkDir,
-		Values:     ctx.Values,
-		ValueFiles: ctxValueFiles,
+		WorkDir:      ctxWorkDir,
+		Values:       ctx.Values,
+		ValueFiles:   ctxValueFiles,
+		StrictValues: ctx.StrictValues,
+		Driver:       ctx.Driver,
 	}
 	return nil
 }
@@ -60,13 +62,21 @@ type Context struct {
 
 	// ValueFiles is a mapping of release value files
 	ValueFiles map[string][]string
+
+	// StrictValues indicates whether to reject release values that are not defined by the chart
+	StrictValues bool
+
+	// Driver is the storage driver with which to record releases: memory, secret or configmap
+	// Releases recorded in secrets or config maps are visible to the helm CLI and other processes.
+	Driver string
 }
 
 // Release returns the context for the given release
 func (c *Context) Release(name string) *ReleaseContext {
 	return &ReleaseContext{
-		Values:     c.Values[name],
-		ValueFiles: c.ValueFiles[name],
+		Values:       c.Values[name],
+		ValueFiles:   c.ValueFiles[name],
+		StrictValues: c.StrictValues,
 	}
 }
 
@@ -77,4 +87,7 @@ type ReleaseContext struct {
 
 	// Values is the release values
 	Values []string
+
+	// StrictValues indicates whether to reject release values that are not defined by the chart
+	StrictValues bool
 }
@@ -0,0 +1,57 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"helm.sh/helm/v3/pkg/action"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+)
+
+// WriteReleases writes the manifest and values of each installed release to the given directory
+// Releases are written to a <namespace>/<release> subdirectory containing manifest.yaml and values.yaml.
+func WriteReleases(dir string) error {
+	for namespace, client := range getClients() {
+		for _, release := range client.Releases() {
+			if err := release.write(filepath.Join(dir, namespace, release.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// write writes the release's manifest and values to the given directory
+func (r *HelmRelease) write(dir string) error {
+	release, err := action.NewGet(r.config).Run(r.Name())
+	if err != nil {
+		// The release is not installed
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(release.Manifest), 0644); err != nil {
+		return err
+	}
+	values, err := yaml.Marshal(release.Config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "values.yaml"), values, 0644)
+}
@@ -32,6 +32,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -56,7 +57,7 @@ func Releases() []*HelmRelease {
 	return Client().Releases()
 }
 
-func newRelease(name string, namespace string, client *kubernetes.Clientset, chart *HelmChart, config *action.Configuration) *HelmRelease {
+func newRelease(name string, namespace string, client kubernetes.Interface, chart *HelmChart, config *action.Configuration) *HelmRelease {
 	ctx := context.Release(name)
 	opts := &values.Options{
 		ValueFiles: ctx.ValueFiles,
@@ -68,29 +69,35 @@ func newRelease(name string, namespace string, client *kubernetes.Clientset, cha
 	}
 
 	return &HelmRelease{
-		namespace: namespace,
-		client:    client,
-		chart:     chart,
-		config:    config,
-		context:   ctx,
-		name:      name,
-		values:    make(map[string]interface{}),
-		overrides: values,
+		namespace:    namespace,
+		client:       client,
+		chart:        chart,
+		config:       config,
+		context:      ctx,
+		name:         name,
+		values:       make(map[string]interface{}),
+		overrides:    values,
+		strictValues: ctx.StrictValues,
 	}
 }
 
 // HelmRelease is a Helm chart release
 type HelmRelease struct {
-	namespace string
-	client    *kubernetes.Clientset
-	chart     *HelmChart
-	config    *action.Configuration
-	context   *ReleaseContext
-	name      string
-	values    map[string]interface{}
-	overrides map[string]interface{}
-	skipCRDs  bool
-	release   *release.Release
+	namespace    string
+	client       kubernetes.Interface
+	chart        *HelmChart
+	config       *action.Configuration
+	context      *ReleaseContext
+	name         string
+	values       map[string]interface{}
+	overrides    map[string]interface{}
+	strictValues bool
+	skipCRDs     bool
+	skipTests    bool
+	version      string
+	repository   string
+	release      *release.Release
+	discovered   bool
 }
 
 // Namespace returns the release namespace
@@ -119,6 +126,19 @@ func (r *HelmRelease) Values() map[string]interface{} {
 	return r.values
 }
 
+// SetStrictValues sets whether to reject values that are not defined by the chart
+// By default, the strict mode given to the command is used.
+func (r *HelmRelease) SetStrictValues(strict bool) *HelmRelease {
+	r.strictValues = strict
+	return r
+}
+
+// StrictValues returns whether values that are not defined by the chart are rejected rather than warned about
+// Values are only checked against the chart's default values if the chart has no values schema.
+func (r *HelmRelease) StrictValues() bool {
+	return r.strictValues
+}
+
 // SetSkipCRDs sets whether to skip CRDs
 func (r *HelmRelease) SetSkipCRDs(skipCRDs bool) *HelmRelease {
 	r.skipCRDs = skipCRDs
@@ -130,6 +150,69 @@ func (r *HelmRelease) SkipCRDs() bool {
 	return r.skipCRDs
 }
 
+// SetSkipTests sets whether to skip test hooks when rendering the release's template
+func (r *HelmRelease) SetSkipTests(skipTests bool) *HelmRelease {
+	r.skipTests = skipTests
+	return r
+}
+
+// SkipTests returns whether test hooks are skipped when rendering the release's template
+func (r *HelmRelease) SkipTests() bool {
+	return r.skipTests
+}
+
+// SetVersion sets the version of the chart to install or upgrade to
+func (r *HelmRelease) SetVersion(version string) *HelmRelease {
+	r.version = version
+	return r
+}
+
+// Version returns the version of the chart to install or upgrade to
+// An empty version indicates the latest version of the chart.
+func (r *HelmRelease) Version() string {
+	return r.version
+}
+
+// SetRepository sets the repository URL from which to install or upgrade the chart
+func (r *HelmRelease) SetRepository(url string) *HelmRelease {
+	r.repository = url
+	return r
+}
+
+// Repository returns the repository URL from which to install or upgrade the chart
+// If no repository is set for the release, the chart's repository is used.
+func (r *HelmRelease) Repository() string {
+	if r.repository != "" {
+		return r.repository
+	}
+	return r.chart.Repository()
+}
+
+// Revision returns the release's current revision, or 0 if the release has not been installed
+func (r *HelmRelease) Revision() int {
+	if r.release == nil {
+		return 0
+	}
+	return r.release.Version
+}
+
+// History returns the revisions of the release, ordered from oldest to newest
+func (r *HelmRelease) History() ([]*HelmRevision, error) {
+	history := action.NewHistory(r.config)
+	releases, err := history.Run(r.Name())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Version < releases[j].Version
+	})
+	revisions := make([]*HelmRevision, len(releases))
+	for i, release := range releases {
+		revisions[i] = newRevision(release)
+	}
+	return revisions, nil
+}
+
 // getResources returns a list of chart resources
 func (r *HelmRelease) getResources() (helm.ResourceList, error) {
 	resources, err := r.config.KubeClient.Build(bytes.NewBufferString(r.release.Manifest), true)
@@ -177,25 +260,109 @@ func (r *HelmRelease) Install(wait bool) error {
 	install := action.NewInstall(r.config)
 	install.Namespace = r.Namespace()
 	install.SkipCRDs = r.SkipCRDs()
-	install.RepoURL = r.chart.Repository()
+	install.RepoURL = r.Repository()
+	install.Version = r.Version()
 	install.ReleaseName = r.Name()
 	install.Wait = wait
 
-	// Locate the chart path
-	path, err := install.ChartPathOptions.LocateChart(r.chart.Name(), settings)
+	chart, err := r.loadChart(&install.ChartPathOptions, install.DependencyUpdate)
+	if err != nil {
+		return err
+	}
+
+	values := r.getValues()
+	if err := r.validateValues(chart, values); err != nil {
+		return err
+	}
+
+	release, err := install.Run(chart, values)
+	if err != nil {
+		return err
+	}
+	r.release = release
+	return nil
+}
+
+// Upgrade upgrades the release to the configured chart version and repository with the release's current values
+func (r *HelmRelease) Upgrade(wait bool) error {
+	if err := r.setContextDir(); err != nil {
+		return err
+	}
+
+	upgrade := action.NewUpgrade(r.config)
+	upgrade.Namespace = r.Namespace()
+	upgrade.RepoURL = r.Repository()
+	upgrade.Version = r.Version()
+	upgrade.Wait = wait
+
+	chart, err := r.loadChart(&upgrade.ChartPathOptions, false)
+	if err != nil {
+		return err
+	}
+
+	values := r.getValues()
+	if err := r.validateValues(chart, values); err != nil {
+		return err
+	}
+
+	release, err := upgrade.Run(r.Name(), chart, values)
 	if err != nil {
 		return err
 	}
+	r.release = release
+	return nil
+}
+
+// Rollback rolls the release back to the given revision
+// If the revision is 0, the release is rolled back to its previous revision.
+func (r *HelmRelease) Rollback(revision int, wait bool) error {
+	if err := r.setContextDir(); err != nil {
+		return err
+	}
+
+	rollback := action.NewRollback(r.config)
+	rollback.Version = revision
+	rollback.Wait = wait
+	if err := rollback.Run(r.Name()); err != nil {
+		return err
+	}
+
+	// Rolling back creates a new revision of the release
+	release, err := action.NewGet(r.config).Run(r.Name())
+	if err != nil {
+		return err
+	}
+	r.release = release
+	return nil
+}
+
+// loadChart locates and loads the release's chart, verifying its dependencies are present
+// Releases discovered in storage use the chart recorded with the release unless a version or repository is set,
+// since the source from which they were installed is unknown.
+func (r *HelmRelease) loadChart(options *action.ChartPathOptions, dependencyUpdate bool) (*chart.Chart, error) {
+	if r.discovered && r.Version() == "" && r.Repository() == "" {
+		// Subcharts are not recorded with releases
+		if len(r.release.Chart.Metadata.Dependencies) > 0 {
+			return nil, fmt.Errorf("release %s was not installed by this client and its chart %s has dependencies; set the chart version or repository from which to load it", r.Name(), r.release.Chart.Name())
+		}
+		return r.release.Chart, nil
+	}
+
+	// Locate the chart path
+	path, err := options.LocateChart(r.chart.Name(), settings)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check chart dependencies to make sure all are present in /charts
 	chart, err := loader.Load(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	valid, err := isChartInstallable(chart)
 	if !valid {
-		return err
+		return nil, err
 	}
 
 	if req := chart.Metadata.Dependencies; req != nil {
@@ -203,32 +370,30 @@ func (r *HelmRelease) Install(wait bool) error {
 		// As of Helm 2.4.0, this is treated as a stopping condition:
 		// https://github.com/helm/helm/issues/2209
 		if err := action.CheckDependencies(chart, req); err != nil {
-			if install.DependencyUpdate {
+			if dependencyUpdate {
 				man := &downloader.Manager{
 					Out:              os.Stdout,
 					ChartPath:        path,
-					Keyring:          install.ChartPathOptions.Keyring,
+					Keyring:          options.Keyring,
 					SkipUpdate:       false,
 					Getters:          getter.All(cli.New()),
 					RepositoryConfig: settings.RepositoryConfig,
 					RepositoryCache:  settings.RepositoryCache,
 				}
 				if err := man.Update(); err != nil {
-					return err
+					return nil, err
 				}
 			} else {
-				return err
+				return nil, err
 			}
 		}
 	}
+	return chart, nil
+}
 
-	values := mergeMaps(r.overrides, normalize(r.values).(map[string]interface{}))
-	release, err := install.Run(chart, values)
-	if err != nil {
-		return err
-	}
-	r.release = release
-	return nil
+// getValues returns the release's values merged over the values from the context
+func (r *HelmRelease) getValues() map[string]interface{} {
+	return mergeMaps(r.overrides, normalize(r.values).(map[string]interface{}))
 }
 
 // Uninstall uninstalls the Helm chart
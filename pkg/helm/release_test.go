@@ -0,0 +1,291 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io/ioutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	fakerest "k8s.io/client-go/rest/fake"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestChart creates a chart in a temporary directory and returns a chart client for it
+func newTestChart(t *testing.T) (*HelmChart, func()) {
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	path, err := chartutil.Create("test-chart", dir)
+	assert.NoError(t, err)
+
+	return newChart(path, nil, "test", fake.NewSimpleClientset(), newTestConfig(t)), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// newTestConfig returns a Helm configuration that stores releases in memory without connecting to a cluster
+func newTestConfig(t *testing.T) *action.Configuration {
+	return &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          t.Logf,
+	}
+}
+
+func TestUpgradeAndRollback(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+
+	release := chart.Release("test")
+	assert.Equal(t, 0, release.Revision())
+	assert.NoError(t, release.Install(false))
+	assert.Equal(t, 1, release.Revision())
+
+	release.Set("replicaCount", 3)
+	assert.NoError(t, release.Upgrade(false))
+	assert.Equal(t, 2, release.Revision())
+	assert.Equal(t, 3, release.release.Config["replicaCount"])
+
+	assert.NoError(t, release.Rollback(1, false))
+	assert.Equal(t, 3, release.Revision())
+	assert.Nil(t, release.release.Config["replicaCount"])
+
+	history, err := release.History()
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, 1, history[0].Revision)
+	assert.Equal(t, "superseded", history[0].Status)
+	assert.Equal(t, "test-chart", history[0].Chart)
+	assert.Equal(t, 3, history[2].Revision)
+	assert.Equal(t, "deployed", history[2].Status)
+	assert.Equal(t, "Rollback to 1", history[2].Description)
+}
+
+func TestTest(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+
+	// Replace the chart's test pod with a job, since the fake clientset cannot return pod logs
+	hook := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: "{{ .Release.Name }}-smoke-test"
+  annotations:
+    "helm.sh/hook": test
+spec:
+  template:
+    spec:
+      containers:
+        - name: test
+          image: busybox
+      restartPolicy: Never
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(chart.Name(), "templates", "tests", "test-connection.yaml"), []byte(hook), 0644))
+
+	release := chart.Release("test")
+	assert.NoError(t, release.Install(false))
+	results, err := release.Test()
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "test-smoke-test", results[0].Name)
+	assert.Equal(t, "Job", results[0].Kind)
+	assert.True(t, results[0].Succeeded())
+	assert.False(t, results[0].Completed.Before(results[0].Started))
+}
+
+// logsErrorClient is a clientset that fails to read pod logs
+type logsErrorClient struct {
+	kubernetes.Interface
+}
+
+func (c logsErrorClient) CoreV1() typedcorev1.CoreV1Interface {
+	return logsErrorCoreV1{c.Interface.CoreV1()}
+}
+
+type logsErrorCoreV1 struct {
+	typedcorev1.CoreV1Interface
+}
+
+func (c logsErrorCoreV1) Pods(namespace string) typedcorev1.PodInterface {
+	return logsErrorPods{c.CoreV1Interface.Pods(namespace)}
+}
+
+type logsErrorPods struct {
+	typedcorev1.PodInterface
+}
+
+func (p logsErrorPods) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	client := &fakerest.RESTClient{
+		NegotiatedSerializer: scheme.Codecs,
+		Err:                  errors.New("logs unavailable"),
+	}
+	return client.Get()
+}
+
+func TestTestLogsError(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+	chart.client = logsErrorClient{chart.client}
+
+	// Failing to read a test pod's logs is recorded in its result
+	release := chart.Release("test")
+	assert.NoError(t, release.Install(false))
+	results, err := release.Test()
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Pod", results[0].Kind)
+	assert.True(t, results[0].Succeeded())
+	assert.Contains(t, results[0].LogsError, "logs unavailable")
+}
+
+func TestTemplate(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+
+	template, err := chart.Release("test").
+		Set("replicaCount", 3).
+		Template()
+	assert.NoError(t, err)
+	assert.Len(t, template.Kind("Deployment"), 1)
+	assert.Len(t, template.Kind("Pod"), 1)
+	assert.NotNil(t, template.Object("Pod", "test-test-chart-test-connection"))
+	deployment := template.Object("Deployment", "test-test-chart").(*appsv1.Deployment)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	service := template.Object("Service", "test-test-chart").(*corev1.Service)
+	assert.Equal(t, int32(80), service.Spec.Ports[0].Port)
+	assert.Nil(t, template.Object("Service", "missing"))
+
+	// Render the same chart from a packaged archive
+	chartDir := filepath.Dir(chart.Name())
+	loaded, err := loader.Load(chart.Name())
+	assert.NoError(t, err)
+	archive, err := chartutil.Save(loaded, chartDir)
+	assert.NoError(t, err)
+	packaged, err := newChart(archive, nil, "test", nil, nil).
+		Release("test").
+		Set("replicaCount", 3).
+		Template()
+	assert.NoError(t, err)
+	assert.Equal(t, template.Manifest, packaged.Manifest)
+
+	golden := filepath.Join(chartDir, "golden", "manifest.yaml")
+	os.Setenv(UpdateGoldenEnv, "true")
+	assert.NoError(t, template.CompareGolden(golden))
+	os.Unsetenv(UpdateGoldenEnv)
+	assert.NoError(t, template.CompareGolden(golden))
+	assert.NoError(t, CompareGolden(golden, []byte(packaged.Manifest)))
+	err = CompareGolden(golden, []byte(strings.Replace(template.Manifest, "replicas: 3", "replicas: 1", 1)))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "-   replicas: 3\n+   replicas: 1")
+
+	// Test hooks are only omitted when skipping tests
+	skipped, err := chart.Release("test").
+		SetSkipTests(true).
+		Template()
+	assert.NoError(t, err)
+	assert.Len(t, skipped.Kind("Deployment"), 1)
+	assert.Len(t, skipped.Kind("Pod"), 0)
+	assert.NotContains(t, skipped.Manifest, "test-connection")
+}
+
+func TestValidateValues(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+
+	// Values that are not in the chart's default values are only rejected in strict mode
+	release := chart.Release("test").
+		Set("replicaCount", 3).
+		Set("resources.limits.cpu", "1").
+		Set("backend.replcias", 3).
+		Set("image.repositry", "nginx")
+	_, err := release.Template()
+	assert.NoError(t, err)
+	_, err = release.SetStrictValues(true).Template()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "\n  backend\n  image.repositry")
+	assert.NotContains(t, err.Error(), "resources")
+
+	// Charts with a schema are validated against the schema
+	schema := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "replicaCount": {
+      "type": "integer"
+    }
+  }
+}
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(chart.Name(), "values.schema.json"), []byte(schema), 0644))
+	_, err = chart.Release("test").
+		SetStrictValues(true).
+		Set("backend.replcias", 3).
+		Template()
+	assert.NoError(t, err)
+	_, err = chart.Release("test").
+		Set("replicaCount", "three").
+		Template()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "replicaCount")
+}
+
+func TestValidateAliasedValues(t *testing.T) {
+	chart, cleanup := newTestChart(t)
+	defer cleanup()
+
+	// Add a subchart that's installed under an alias
+	_, err := chartutil.Create("backend", filepath.Join(chart.Name(), "charts"))
+	assert.NoError(t, err)
+	metadata, err := ioutil.ReadFile(filepath.Join(chart.Name(), "Chart.yaml"))
+	assert.NoError(t, err)
+	metadata = append(metadata, []byte(`
+dependencies:
+- name: backend
+  version: 0.1.0
+  alias: primary
+`)...)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(chart.Name(), "Chart.yaml"), metadata, 0644))
+
+	// Values for the subchart are defined under its alias
+	_, err = chart.Release("test").
+		SetStrictValues(true).
+		Set("primary.replicaCount", 3).
+		Set("primary.image.pullPolicy", "Always").
+		Template()
+	assert.NoError(t, err)
+
+	_, err = chart.Release("test").
+		SetStrictValues(true).
+		Set("primary.replcias", 3).
+		Template()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "\n  primary.replcias")
+}
@@ -0,0 +1,116 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"time"
+)
+
+// HelmTestResult is the result of a chart test hook
+type HelmTestResult struct {
+	// Name is the name of the test hook resource
+	Name string
+	// Kind is the kind of the test hook resource, usually a Pod
+	Kind string
+	// Phase is the phase of the test hook's last run: Succeeded, Failed, Running or Unknown
+	Phase string
+	// Started is the time at which the test hook was started
+	Started time.Time
+	// Completed is the time at which the test hook completed
+	Completed time.Time
+	// Logs are the logs of the test hook pod, if the pod still exists
+	Logs string
+	// LogsError is the error that prevented the test hook pod's logs from being read, if any
+	LogsError string
+}
+
+// Succeeded returns whether the test hook succeeded
+func (r *HelmTestResult) Succeeded() bool {
+	return r.Phase == string(release.HookPhaseSucceeded)
+}
+
+// Test runs the release's chart test hooks in the same way as the `helm test` command
+// The result and logs of each test hook are returned even if a test fails, in which case a non-nil error is
+// also returned. Logs cannot be returned for test pods deleted by their hook delete policy, and failures to read a
+// test pod's logs are recorded in its result rather than returned.
+func (r *HelmRelease) Test() ([]*HelmTestResult, error) {
+	if err := r.setContextDir(); err != nil {
+		return nil, err
+	}
+
+	test := action.NewReleaseTesting(r.config)
+	test.Namespace = r.Namespace()
+	rel, testErr := test.Run(r.Name())
+	if rel == nil {
+		return nil, testErr
+	}
+
+	var results []*HelmTestResult
+	for _, hook := range rel.Hooks {
+		if !isTestHook(hook) {
+			continue
+		}
+		result := &HelmTestResult{
+			Name:      hook.Name,
+			Kind:      hook.Kind,
+			Phase:     string(hook.LastRun.Phase),
+			Started:   hook.LastRun.StartedAt.Time,
+			Completed: hook.LastRun.CompletedAt.Time,
+		}
+		if hook.Kind == "Pod" && hook.LastRun.Phase != "" {
+			logs, err := r.getPodLogs(hook.Name)
+			if err != nil {
+				result.LogsError = err.Error()
+			}
+			result.Logs = logs
+		}
+		results = append(results, result)
+	}
+	return results, testErr
+}
+
+// isTestHook returns whether the given hook is run by `helm test`
+func isTestHook(hook *release.Hook) bool {
+	for _, event := range hook.Events {
+		if event == release.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
+// getPodLogs returns the logs of the given pod in the release's namespace
+// If the pod no longer exists, no logs are returned.
+func (r *HelmRelease) getPodLogs(name string) (string, error) {
+	stream, err := r.client.CoreV1().Pods(r.Namespace()).GetLogs(name, &corev1.PodLogOptions{}).Stream()
+	if k8serrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, stream); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
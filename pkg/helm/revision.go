@@ -0,0 +1,55 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"helm.sh/helm/v3/pkg/release"
+	"time"
+)
+
+func newRevision(release *release.Release) *HelmRevision {
+	revision := &HelmRevision{
+		Revision: release.Version,
+	}
+	if release.Info != nil {
+		revision.Status = release.Info.Status.String()
+		revision.Description = release.Info.Description
+		revision.Updated = release.Info.LastDeployed.Time
+	}
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		revision.Chart = release.Chart.Metadata.Name
+		revision.Version = release.Chart.Metadata.Version
+		revision.AppVersion = release.Chart.Metadata.AppVersion
+	}
+	return revision
+}
+
+// HelmRevision is a revision of a Helm release
+type HelmRevision struct {
+	// Revision is the revision number
+	Revision int
+	// Status is the status of the revision, e.g. deployed or superseded
+	Status string
+	// Chart is the name of the chart deployed in the revision
+	Chart string
+	// Version is the version of the chart deployed in the revision
+	Version string
+	// AppVersion is the version of the application deployed in the revision
+	AppVersion string
+	// Description is a description of the revision, e.g. "Upgrade complete"
+	Description string
+	// Updated is the time at which the revision was deployed
+	Updated time.Time
+}
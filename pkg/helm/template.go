@@ -0,0 +1,199 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"helm.sh/helm/v3/pkg/action"
+	"io"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set, causes golden files to be updated
+const UpdateGoldenEnv = "HELMIT_UPDATE_GOLDEN"
+
+// HelmTemplate is the set of resources rendered from a chart
+type HelmTemplate struct {
+	// Manifest is the rendered YAML manifest
+	Manifest string
+	// Objects are the rendered objects grouped by kind
+	// Objects of kinds known to the Kubernetes client are decoded into their typed structs, e.g. *appsv1.Deployment.
+	// Objects of other kinds, e.g. custom resources, are decoded as *unstructured.Unstructured.
+	Objects map[string][]runtime.Object
+}
+
+// Kind returns the rendered objects of the given kind
+func (t *HelmTemplate) Kind(kind string) []runtime.Object {
+	return t.Objects[kind]
+}
+
+// Object returns the rendered object of the given kind and name, or nil if no such object was rendered
+func (t *HelmTemplate) Object(kind string, name string) runtime.Object {
+	for _, object := range t.Objects[kind] {
+		if accessor, err := meta.Accessor(object); err == nil && accessor.GetName() == name {
+			return object
+		}
+	}
+	return nil
+}
+
+// CompareGolden compares the rendered manifest to the contents of the given golden file
+// If the HELMIT_UPDATE_GOLDEN environment variable is set, the golden file is written with the rendered manifest
+// instead. An error describing the differences is returned if the manifest does not match the golden file.
+func (t *HelmTemplate) CompareGolden(file string) error {
+	return CompareGolden(file, []byte(t.Manifest))
+}
+
+// CompareGolden compares the given bytes to the contents of the given golden file
+// If the HELMIT_UPDATE_GOLDEN environment variable is set, the golden file is written with the given bytes instead.
+// An error listing the lines that differ is returned if the bytes do not match the golden file.
+func CompareGolden(file string, actual []byte) error {
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, actual, 0644)
+	}
+
+	expected, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(expected, actual) {
+		return nil
+	}
+
+	expectedLines := strings.Split(string(expected), "\n")
+	actualLines := strings.Split(string(actual), "\n")
+	lines := len(expectedLines)
+	if len(actualLines) > lines {
+		lines = len(actualLines)
+	}
+	diff := &strings.Builder{}
+	for i := 0; i < lines; i++ {
+		var expectedLine, actualLine string
+		if i < len(expectedLines) {
+			expectedLine = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			actualLine = actualLines[i]
+		}
+		if expectedLine != actualLine {
+			fmt.Fprintf(diff, "line %d:\n- %s\n+ %s\n", i+1, expectedLine, actualLine)
+		}
+	}
+	return fmt.Errorf("golden file %s does not match (set %s to update it)\n%s", file, UpdateGoldenEnv, diff.String())
+}
+
+// Template renders the release's chart with the release's values without connecting to the cluster
+// Charts can be rendered from a local chart directory, a packaged .tgz chart, or a repository. Hooks are rendered
+// along with the chart's resources, as with the `helm template` command. Test hooks are omitted if the release
+// skips tests, as with `helm template --skip-tests`.
+func (r *HelmRelease) Template() (*HelmTemplate, error) {
+	if err := r.setContextDir(); err != nil {
+		return nil, err
+	}
+
+	// Client only installs replace the configuration's clients, so render with a configuration of its own
+	install := action.NewInstall(&action.Configuration{
+		Log: log.Printf,
+	})
+	install.Namespace = r.Namespace()
+	install.SkipCRDs = r.SkipCRDs()
+	install.RepoURL = r.Repository()
+	install.Version = r.Version()
+	install.ReleaseName = r.Name()
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+
+	chart, err := r.loadChart(&install.ChartPathOptions, false)
+	if err != nil {
+		return nil, err
+	}
+
+	values := r.getValues()
+	if err := r.validateValues(chart, values); err != nil {
+		return nil, err
+	}
+
+	rel, err := install.Run(chart, values)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &strings.Builder{}
+	manifest.WriteString(rel.Manifest)
+	for _, hook := range rel.Hooks {
+		if !r.SkipTests() || !isTestHook(hook) {
+			fmt.Fprintf(manifest, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+		}
+	}
+
+	objects, err := decodeManifest(manifest.String())
+	if err != nil {
+		return nil, err
+	}
+	return &HelmTemplate{
+		Manifest: manifest.String(),
+		Objects:  objects,
+	}, nil
+}
+
+// decodeManifest decodes the objects in the given YAML manifest, grouped by kind
+func decodeManifest(manifest string) (map[string][]runtime.Object, error) {
+	objects := make(map[string][]runtime.Object)
+	reader := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		bytes, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if string(bytes) == "null" {
+			continue
+		}
+
+		object, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(bytes, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			u := &unstructured.Unstructured{}
+			if err := u.UnmarshalJSON(bytes); err != nil {
+				return nil, err
+			}
+			objects[u.GetKind()] = append(objects[u.GetKind()], u)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		objects[gvk.Kind] = append(objects[gvk.Kind], object)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sort"
+	"strings"
+)
+
+// globalValues is the key of the values shared by a chart and all its subcharts
+const globalValues = "global"
+
+// validateValues checks the release's values against the chart before it is installed
+// If the chart or any of its subcharts has a values.schema.json, the values are validated against the schemas.
+// Otherwise, values that are not present in the chart's default values are reported, failing the install only
+// in strict mode.
+func (r *HelmRelease) validateValues(chart *chart.Chart, values map[string]interface{}) error {
+	if hasSchema(chart) {
+		coalesced, err := chartutil.CoalesceValues(chart, values)
+		if err != nil {
+			return err
+		}
+		if err := chartutil.ValidateAgainstSchema(chart, coalesced); err != nil {
+			return fmt.Errorf("invalid values for release %s: %s", r.Name(), err)
+		}
+		return nil
+	}
+
+	defaults, err := chartutil.CoalesceValues(chart, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	addAliasDefaults(chart, defaults)
+	paths := getUnknownPaths(defaults, values, "")
+	if len(paths) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("values for release %s are not defined by chart %s:\n  %s", r.Name(), chart.Name(), strings.Join(paths, "\n  "))
+	if r.StrictValues() {
+		return errors.New(message)
+	}
+	fmt.Printf("WARNING: %s\n", message)
+	return nil
+}
+
+// hasSchema returns whether the chart or any of its subcharts has a values schema
+func hasSchema(chart *chart.Chart) bool {
+	if len(chart.Schema) > 0 {
+		return true
+	}
+	for _, dependency := range chart.Dependencies() {
+		if hasSchema(dependency) {
+			return true
+		}
+	}
+	return false
+}
+
+// addAliasDefaults adds the default values of aliased subcharts under their aliases
+// Subcharts are only renamed to their aliases when dependencies are processed on install, so their coalesced
+// defaults are keyed by the subchart names.
+func addAliasDefaults(chart *chart.Chart, defaults map[string]interface{}) {
+	for _, dependency := range chart.Dependencies() {
+		if values, ok := asMap(defaults[dependency.Name()]); ok {
+			addAliasDefaults(dependency, values)
+		}
+	}
+	if chart.Metadata == nil {
+		return
+	}
+	for _, dependency := range chart.Metadata.Dependencies {
+		if dependency.Alias == "" {
+			continue
+		}
+		values, ok := asMap(defaults[dependency.Name])
+		if !ok {
+			continue
+		}
+		if aliasValues, ok := asMap(defaults[dependency.Alias]); ok {
+			defaults[dependency.Alias] = chartutil.CoalesceTables(aliasValues, values)
+		} else {
+			defaults[dependency.Alias] = values
+		}
+	}
+}
+
+// getUnknownPaths returns the sorted paths of the values that are not present in the defaults
+// Values beneath a default that is not a map or is an empty map are free-form and are not checked.
+func getUnknownPaths(defaults, values map[string]interface{}, prefix string) []string {
+	paths := make([]string, 0)
+	for key, value := range values {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		} else if key == globalValues {
+			continue
+		}
+
+		defaultValue, ok := defaults[key]
+		if !ok {
+			paths = append(paths, path)
+			continue
+		}
+		defaultMap, ok := asMap(defaultValue)
+		if !ok || len(defaultMap) == 0 {
+			continue
+		}
+		valueMap, ok := asMap(value)
+		if !ok {
+			continue
+		}
+		paths = append(paths, getUnknownPaths(defaultMap, valueMap, path)...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// asMap returns the value as a map if it is one
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case chartutil.Values:
+		return v, true
+	}
+	return nil, false
+}
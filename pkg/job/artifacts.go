@@ -0,0 +1,79 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/onosproject/helmit/pkg/util/files"
+	"github.com/onosproject/helmit/pkg/util/logging"
+	"os"
+)
+
+// CollectArtifacts copies the artifacts written by the given job to the local destination directory
+// Once the artifacts have been copied, the job's artifacts container is stopped to allow the job to complete. The
+// container is stopped even if the artifacts cannot be copied.
+func (n *Runner) CollectArtifacts(job *Job, dest string) error {
+	if job.ArtifactsDir == "" {
+		return nil
+	}
+
+	step := logging.NewStep(job.ID, "Collect artifacts")
+	step.Start()
+
+	pod, err := n.getPod(job)
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+
+	err = os.MkdirAll(dest, 0755)
+	if err == nil {
+		err = files.Download(n).
+			From(ArtifactsPath).
+			To(dest).
+			On(pod.Name, artifactsContainer).
+			Do()
+	}
+	if doneErr := n.stopArtifacts(job, pod.Name); err == nil {
+		err = doneErr
+	}
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+	step.Complete()
+	return nil
+}
+
+// ReleaseArtifacts stops the given job's artifacts container without copying its artifacts
+// Jobs that fail before their artifacts can be collected must be released to allow their pods to complete.
+func (n *Runner) ReleaseArtifacts(job *Job) error {
+	if job.ArtifactsDir == "" {
+		return nil
+	}
+	pod, err := n.getPod(job)
+	if err != nil {
+		return err
+	}
+	return n.stopArtifacts(job, pod.Name)
+}
+
+// stopArtifacts writes the done file to the artifacts container of the given pod to allow it to exit
+func (n *Runner) stopArtifacts(job *Job, pod string) error {
+	return files.Echo(n).
+		String(job.ID).
+		To(artifactsDoneFile).
+		On(pod, artifactsContainer).
+		Do()
+}
@@ -0,0 +1,124 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/util/logging"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// CanceledExitCode is the exit code of a job that was canceled
+const CanceledExitCode = 130
+
+// cancelGracePeriod is the time a canceled job is given to tear down its resources before it's killed
+const cancelGracePeriod = 5 * time.Minute
+
+// ErrCanceled is returned when a job is canceled
+var ErrCanceled = errors.New("job canceled")
+
+// OnCancel calls the given function when the process receives an interrupt or termination signal
+// If a second signal is received, the process exits immediately with the CanceledExitCode. The returned
+// function stops listening for signals.
+func OnCancel(f func()) func() {
+	signalCh := make(chan os.Signal, 2)
+	stopCh := make(chan struct{})
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signalCh:
+			go f()
+		case <-stopCh:
+			return
+		}
+		select {
+		case <-signalCh:
+			fmt.Println("Canceled")
+			os.Exit(CanceledExitCode)
+		case <-stopCh:
+		}
+	}()
+	return func() {
+		signal.Stop(signalCh)
+		close(stopCh)
+	}
+}
+
+// Cancel cancels all operations waiting on jobs in the namespace
+// Once canceled, any pending or future waits return ErrCanceled.
+func (n *Runner) Cancel() {
+	n.cancelOnce.Do(func() {
+		close(n.cancelCh)
+	})
+}
+
+// CancelJob cancels the given job
+// The job's pod is deleted with a grace period long enough to allow the job to tear down the resources it
+// created. Waits on the job continue until the job exits or its pod is removed.
+func (n *Runner) CancelJob(job *Job) error {
+	step := logging.NewStep(job.ID, "Cancel job")
+	step.Start()
+
+	// Delete the job without its pod to prevent the pod from being deleted without a grace period
+	orphan := metav1.DeletePropagationOrphan
+	err := n.clientset.BatchV1().Jobs(n.Namespace()).Delete(job.ID, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		step.Fail(err)
+		return err
+	}
+
+	// The job is canceled after the namespace's waits may have been canceled, so the watch is not canceled
+	pods, err := n.watchPods(job, PhaseComplete, nil)
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+	pod, err := pods.get(job, func(pod *corev1.Pod) bool {
+		return true
+	})
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+
+	if pod != nil {
+		gracePeriod := int64(cancelGracePeriod / time.Second)
+		err = n.clientset.CoreV1().Pods(n.Namespace()).Delete(pod.Name, &metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			step.Fail(err)
+			return err
+		}
+	}
+
+	// Once the pod has been removed, cancel any remaining waits
+	go func() {
+		if err := pods.awaitRemoved(job); err == nil {
+			n.Cancel()
+		}
+	}()
+	step.Complete()
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"github.com/onosproject/helmit/pkg/kubernetes"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Cleanup garbage collects resources left behind by jobs that were not torn down
+// Namespaces created for jobs are deleted once they're older than the given age, and service account subjects
+// for namespaces that no longer exist are removed from job cluster role bindings. Namespaces belonging to runs
+// that are still active are never deleted. If dryRun is true, the resources are listed but not deleted.
+func Cleanup(out io.Writer, olderThan time.Duration, dryRun bool) error {
+	client, err := kubernetes.NewForNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	runs, err := listRuns(client.Clientset(), false)
+	if err != nil {
+		return err
+	}
+	return cleanup(client.Clientset(), out, olderThan, dryRun, runs)
+}
+
+// cleanup garbage collects resources left behind by jobs, skipping the namespaces of the given active runs
+func cleanup(client k8s.Interface, out io.Writer, olderThan time.Duration, dryRun bool, activeRuns []RunInfo) error {
+	writer := new(tabwriter.Writer)
+	writer.Init(out, 0, 0, 3, ' ', tabwriter.FilterHTML)
+	defer writer.Flush()
+	fmt.Fprintln(writer, "KIND\tNAME\tAGE")
+
+	// Delete job namespaces older than the configured age
+	// Job namespaces are labeled with their own name, which distinguishes them from other namespaces with a test label.
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: "test",
+	})
+	if err != nil {
+		return err
+	}
+
+	deletedNamespaces := make(map[string]bool)
+	for _, ns := range namespaces.Items {
+		age := time.Since(ns.CreationTimestamp.Time)
+		if ns.Name == namespace || ns.Labels["test"] != ns.Name || age < olderThan ||
+			ns.Status.Phase == corev1.NamespaceTerminating || isRunNamespace(ns.Name, activeRuns) {
+			continue
+		}
+		deletedNamespaces[ns.Name] = true
+		fmt.Fprintln(writer, fmt.Sprintf("Namespace\t%s\t%s", ns.Name, age.Round(time.Second)))
+		if !dryRun {
+			err := client.CoreV1().Namespaces().Delete(ns.Name, &metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	// Namespaces may have been created since they were listed, so look up each subject's namespace
+	activeNamespaces := make(map[string]bool)
+	isActive := func(name string) (bool, error) {
+		if deletedNamespaces[name] {
+			return false, nil
+		}
+		active, ok := activeNamespaces[name]
+		if ok {
+			return active, nil
+		}
+		ns, err := client.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		active = err == nil && ns.Status.Phase != corev1.NamespaceTerminating
+		activeNamespaces[name] = active
+		return active, nil
+	}
+
+	// Remove subjects for deleted namespaces from job cluster role bindings
+	roleBindings, err := client.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	boundRoles := make(map[string]bool)
+	for _, roleBinding := range roleBindings.Items {
+		if roleBinding.RoleRef.Kind != "ClusterRole" ||
+			(roleBinding.RoleRef.Name != clusterRole && !isCustomRoleName(roleBinding.RoleRef.Name)) {
+			continue
+		}
+		boundRoles[roleBinding.RoleRef.Name] = true
+
+		staleSubjects := make(map[rbacv1.Subject]bool)
+		for _, subject := range roleBinding.Subjects {
+			if subject.Kind != "ServiceAccount" {
+				continue
+			}
+			active, err := isActive(subject.Namespace)
+			if err != nil {
+				return err
+			}
+			if !active {
+				staleSubjects[subject] = true
+				fmt.Fprintln(writer, fmt.Sprintf("ClusterRoleBinding subject\t%s/%s/%s\t", roleBinding.Name, subject.Namespace, subject.Name))
+			}
+		}
+
+		// Bindings of roles for custom rules are deleted along with their roles once no subjects remain
+		unused := isCustomRoleName(roleBinding.Name) && len(staleSubjects) == len(roleBinding.Subjects)
+		if unused {
+			fmt.Fprintln(writer, fmt.Sprintf("ClusterRoleBinding\t%s\t", roleBinding.Name))
+			fmt.Fprintln(writer, fmt.Sprintf("ClusterRole\t%s\t", roleBinding.RoleRef.Name))
+		}
+		if dryRun {
+			continue
+		}
+		if len(staleSubjects) > 0 {
+			err := removeClusterRoleBindingSubjects(client, roleBinding.Name, func(subject rbacv1.Subject) bool {
+				return staleSubjects[subject]
+			})
+			if err != nil {
+				return err
+			}
+		} else if unused {
+			if err := deleteClusterRole(client, &roleBinding); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Delete roles for custom rules that are not bound to any namespace
+	roles, err := client.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, role := range roles.Items {
+		age := time.Since(role.CreationTimestamp.Time)
+		if !isCustomRoleName(role.Name) || boundRoles[role.Name] || age < olderThan {
+			continue
+		}
+		fmt.Fprintln(writer, fmt.Sprintf("ClusterRole\t%s\t%s", role.Name, age.Round(time.Second)))
+		if !dryRun {
+			err := client.RbacV1().ClusterRoles().Delete(role.Name, &metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isRunNamespace returns whether the namespace was created by any of the given runs
+// Namespaces created by a run are prefixed with the run ID.
+func isRunNamespace(name string, runs []RunInfo) bool {
+	for _, run := range runs {
+		if strings.HasPrefix(name, run.ID+"-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+// newTestNamespace returns a namespace created the given time ago with the given test label
+func newTestNamespace(name string, label string, age time.Duration) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Labels:            map[string]string{"test": label},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+	}
+}
+
+// newServiceAccountSubject returns the subject for the service account of the given job namespace
+func newServiceAccountSubject(namespace string) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:      "ServiceAccount",
+		Name:      namespace,
+		Namespace: namespace,
+	}
+}
+
+// newCleanupObjects returns the objects left behind by stale, active and unrelated runs
+func newCleanupObjects() []runtime.Object {
+	return []runtime.Object{
+		newTestNamespace(namespace, namespace, 48*time.Hour),
+		newTestNamespace("stale-1-suite", "stale-1-suite", 48*time.Hour),
+		newTestNamespace("young-1-suite", "young-1-suite", time.Minute),
+		newTestNamespace("active-1-suite", "active-1-suite", 48*time.Hour),
+		newTestNamespace("not-helmit", "true", 48*time.Hour),
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterRole,
+			},
+			Subjects: []rbacv1.Subject{
+				newServiceAccountSubject("stale-1-suite"),
+				newServiceAccountSubject("deleted-1-suite"),
+				newServiceAccountSubject("young-1-suite"),
+				newServiceAccountSubject("active-1-suite"),
+				{
+					Kind: "User",
+					Name: "admin",
+				},
+			},
+			RoleRef: rbacv1.RoleRef{
+				Kind: "ClusterRole",
+				Name: clusterRole,
+			},
+		},
+		newCustomClusterRole(clusterRole+"-stale", 48*time.Hour),
+		newCustomClusterRoleBinding(clusterRole+"-stale", newServiceAccountSubject("deleted-1-suite")),
+		newCustomClusterRole(clusterRole+"-empty", 48*time.Hour),
+		newCustomClusterRoleBinding(clusterRole + "-empty"),
+		newCustomClusterRole(clusterRole+"-active", 48*time.Hour),
+		newCustomClusterRoleBinding(clusterRole+"-active", newServiceAccountSubject("active-1-suite")),
+		newCustomClusterRole(clusterRole+"-unbound", 48*time.Hour),
+		newCustomClusterRole(clusterRole+"-young", time.Minute),
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "other",
+			},
+			Subjects: []rbacv1.Subject{
+				newServiceAccountSubject("deleted-1-suite"),
+			},
+			RoleRef: rbacv1.RoleRef{
+				Kind: "ClusterRole",
+				Name: "other",
+			},
+		},
+	}
+}
+
+// newCustomClusterRole returns a cluster role for custom rules created the given time ago
+func newCustomClusterRole(name string, age time.Duration) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+	}
+}
+
+// newCustomClusterRoleBinding returns a binding of the named cluster role for custom rules to the given subjects
+func newCustomClusterRoleBinding(name string, subjects ...rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Subjects: subjects,
+		RoleRef: rbacv1.RoleRef{
+			Kind: "ClusterRole",
+			Name: name,
+		},
+	}
+}
+
+// getClusterRoleNames returns the names of the cluster roles and cluster role bindings in the given clientset
+func getClusterRoleNames(t *testing.T, clientset *fake.Clientset) ([]string, []string) {
+	roles, err := clientset.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	roleNames := make([]string, len(roles.Items))
+	for i, role := range roles.Items {
+		roleNames[i] = role.Name
+	}
+	bindings, err := clientset.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	bindingNames := make([]string, len(bindings.Items))
+	for i, binding := range bindings.Items {
+		bindingNames[i] = binding.Name
+	}
+	return roleNames, bindingNames
+}
+
+// getNamespaceNames returns the names of the namespaces in the given clientset
+func getNamespaceNames(t *testing.T, clientset *fake.Clientset) []string {
+	namespaces, err := clientset.CoreV1().Namespaces().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	names := make([]string, len(namespaces.Items))
+	for i, ns := range namespaces.Items {
+		names[i] = ns.Name
+	}
+	return names
+}
+
+// getSubjectNamespaces returns the namespaces of the subjects of the given cluster role binding
+func getSubjectNamespaces(t *testing.T, clientset *fake.Clientset, name string) []string {
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	namespaces := make([]string, len(binding.Subjects))
+	for i, subject := range binding.Subjects {
+		namespaces[i] = subject.Namespace
+	}
+	return namespaces
+}
+
+func TestCleanup(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newCleanupObjects()...)
+	activeRuns := []RunInfo{{ID: "active", Active: true}}
+	out := &bytes.Buffer{}
+	assert.NoError(t, cleanup(clientset, out, time.Hour, false, activeRuns))
+
+	// Only namespaces owned by helmit that are old and not part of an active run are deleted
+	assert.ElementsMatch(t, []string{namespace, "young-1-suite", "active-1-suite", "not-helmit"}, getNamespaceNames(t, clientset))
+	assert.Contains(t, out.String(), "stale-1-suite")
+	assert.NotContains(t, out.String(), "not-helmit")
+
+	// Subjects of deleted and missing namespaces are removed from job cluster role bindings only
+	assert.Equal(t, []string{"young-1-suite", "active-1-suite", ""}, getSubjectNamespaces(t, clientset, clusterRole))
+	assert.Equal(t, []string{"deleted-1-suite"}, getSubjectNamespaces(t, clientset, "other"))
+
+	// Roles for custom rules are deleted with their bindings once no namespace is bound to them
+	roles, bindings := getClusterRoleNames(t, clientset)
+	assert.ElementsMatch(t, []string{clusterRole + "-active", clusterRole + "-young"}, roles)
+	assert.ElementsMatch(t, []string{clusterRole, clusterRole + "-active", "other"}, bindings)
+	assert.Regexp(t, `ClusterRole\s+`+clusterRole+`-unbound`, out.String())
+}
+
+func TestCleanupDryRun(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newCleanupObjects()...)
+	out := &bytes.Buffer{}
+	assert.NoError(t, cleanup(clientset, out, time.Hour, true, nil))
+	assert.Contains(t, out.String(), "stale-1-suite")
+	assert.Contains(t, out.String(), "active-1-suite")
+	assert.Contains(t, out.String(), clusterRole+"/deleted-1-suite/deleted-1-suite")
+	assert.Regexp(t, `ClusterRoleBinding\s+`+clusterRole+`-empty`, out.String())
+	assert.Regexp(t, `ClusterRole\s+`+clusterRole+`-stale`, out.String())
+
+	// Nothing is modified in a dry run
+	for _, action := range clientset.Actions() {
+		assert.Contains(t, []string{"get", "list"}, action.GetVerb())
+	}
+	assert.Len(t, getNamespaceNames(t, clientset), 5)
+	assert.Len(t, getSubjectNamespaces(t, clientset, clusterRole), 5)
+}
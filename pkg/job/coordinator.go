@@ -14,17 +14,17 @@
 
 package job
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
 
 const namespace = "kube-test"
 
-// Run runs the job
+// Run runs the job and exits with the job's exit code
 func Run(job *Job) error {
-	coordinator := NewCoordinator()
-	if err := coordinator.CreateNamespace(); err != nil {
-		return err
-	}
-	status, err := coordinator.RunJob(job)
+	status, err := RunAndWait(job)
 	if err != nil {
 		return err
 	}
@@ -32,7 +32,40 @@ func Run(job *Job) error {
 	return nil
 }
 
+// RunAndWait runs the job and returns its exit code once the job's artifacts have been collected
+func RunAndWait(job *Job) (int, error) {
+	coordinator := NewCoordinator(job.RBAC)
+	if err := coordinator.CreateNamespace(); err != nil {
+		return 0, err
+	}
+
+	// When the process is interrupted, cancel the coordinator job to allow it to tear down its resources
+	var canceled int32
+	stop := OnCancel(func() {
+		atomic.StoreInt32(&canceled, 1)
+		_ = coordinator.CancelJob(job)
+	})
+	status, err := coordinator.RunJob(job)
+	stop()
+	if atomic.LoadInt32(&canceled) == 1 {
+		os.Exit(CanceledExitCode)
+	}
+	if err != nil {
+		_ = coordinator.ReleaseArtifacts(job)
+		return 0, err
+	}
+
+	// Copy the artifacts collected by the coordinator into a directory for the run
+	if job.ArtifactsDir != "" {
+		dir := filepath.Join(job.ArtifactsDir, job.ID)
+		if err := coordinator.CollectArtifacts(job, dir); err == nil {
+			PrintDiagnostics(os.Stdout, dir)
+		}
+	}
+	return status, nil
+}
+
 // NewCoordinator returns a new test job coordinator
-func NewCoordinator() *Runner {
-	return newRunner(namespace, false)
+func NewCoordinator(rbac *RBAC) *Runner {
+	return newRunner(namespace, false, rbac)
 }
@@ -0,0 +1,295 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/helm"
+	"github.com/onosproject/helmit/pkg/util/logging"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/describe/versioned"
+	"os"
+	"path/filepath"
+	"sort"
+	"sigs.k8s.io/yaml"
+	"text/tabwriter"
+)
+
+// DiagnosticsDir is the name of the directory within a suite's artifacts to which diagnostics are written
+const DiagnosticsDir = "diagnostics"
+
+// redacted is the value with which secret data is replaced in diagnostics
+const redacted = "REDACTED"
+
+// DefaultArtifactsDir returns the local directory to which artifacts and diagnostics are copied if none is configured
+func DefaultArtifactsDir() string {
+	return filepath.Join(os.TempDir(), "helmit-artifacts")
+}
+
+// CaptureReleases writes the manifests and values of the releases installed by this process to its diagnostics
+// Releases are only captured if the job collects artifacts.
+func CaptureReleases() {
+	if _, err := os.Stat(ArtifactsPath); err != nil {
+		return
+	}
+	if err := helm.WriteReleases(filepath.Join(ArtifactsPath, DiagnosticsDir, "releases")); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// PrintDiagnostics prints the paths of the diagnostics captured within the given artifacts directory
+func PrintDiagnostics(out io.Writer, dir string) {
+	var paths []string
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && info.Name() == DiagnosticsDir {
+			paths = append(paths, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "Diagnostics were captured to:")
+	for _, path := range paths {
+		fmt.Fprintf(out, "  %s\n", path)
+	}
+}
+
+// CaptureDiagnostics writes a snapshot of the state of the namespace to the given directory
+// The snapshot includes the YAML of every object in the namespace, the namespace's events, a description of
+// each pod, and the current and previous logs of each container. Secret data is redacted. Diagnostics are
+// captured on a best effort basis: failures to capture individual resources are written to errors.txt.
+func (n *Runner) CaptureDiagnostics(dest string) error {
+	step := logging.NewStep(n.Namespace(), "Capture diagnostics")
+	step.Start()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		step.Fail(err)
+		return err
+	}
+
+	errs := &bytes.Buffer{}
+	if err := n.captureObjects(filepath.Join(dest, "objects"), errs); err != nil {
+		fmt.Fprintf(errs, "objects: %v\n", err)
+	}
+	if err := n.captureEvents(filepath.Join(dest, "events.txt")); err != nil {
+		fmt.Fprintf(errs, "events: %v\n", err)
+	}
+	if err := n.capturePods(dest, errs); err != nil {
+		fmt.Fprintf(errs, "pods: %v\n", err)
+	}
+	if errs.Len() > 0 {
+		if err := ioutil.WriteFile(filepath.Join(dest, "errors.txt"), errs.Bytes(), 0644); err != nil {
+			step.Fail(err)
+			return err
+		}
+	}
+	step.Complete()
+	return nil
+}
+
+// captureObjects writes the YAML of every listable object in the namespace to a file per resource type
+func (n *Runner) captureObjects(dest string, errs io.Writer) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	client, err := dynamic.NewForConfig(n.Config())
+	if err != nil {
+		return err
+	}
+
+	// Discovery may return partial results if some API groups are unavailable
+	resourceLists, err := n.clientset.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		fmt.Fprintf(errs, "discovery: %v\n", err)
+	}
+
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if !isListable(resource) {
+				continue
+			}
+			gvr := groupVersion.WithResource(resource.Name)
+			list, err := client.Resource(gvr).Namespace(n.Namespace()).List(metav1.ListOptions{})
+			if err != nil {
+				fmt.Fprintf(errs, "%s: %v\n", gvr.GroupResource(), err)
+				continue
+			}
+			if len(list.Items) == 0 {
+				continue
+			}
+
+			buf := &bytes.Buffer{}
+			for _, item := range list.Items {
+				if gvr.Group == "" && gvr.Resource == "secrets" {
+					redactSecret(&item)
+				}
+				bytes, err := yaml.Marshal(item.Object)
+				if err != nil {
+					fmt.Fprintf(errs, "%s/%s: %v\n", gvr.GroupResource(), item.GetName(), err)
+					continue
+				}
+				buf.WriteString("---\n")
+				buf.Write(bytes)
+			}
+			file := filepath.Join(dest, gvr.GroupResource().String()+".yaml")
+			if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isListable returns whether objects of the given resource type can be listed
+func isListable(resource metav1.APIResource) bool {
+	for _, verb := range resource.Verbs {
+		if verb == "list" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecret replaces the values of the given secret's data
+func redactSecret(secret *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		data, ok, _ := unstructured.NestedMap(secret.Object, field)
+		if !ok {
+			continue
+		}
+		for key := range data {
+			data[key] = redacted
+		}
+		_ = unstructured.SetNestedMap(secret.Object, data, field)
+	}
+	annotations := secret.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		annotations[corev1.LastAppliedConfigAnnotation] = redacted
+		secret.SetAnnotations(annotations)
+	}
+}
+
+// captureEvents writes the events in the namespace to the given file, ordered by time
+func (n *Runner) captureEvents(file string) error {
+	events, err := n.clientset.CoreV1().Events(n.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+	})
+
+	buf := &bytes.Buffer{}
+	writer := new(tabwriter.Writer)
+	writer.Init(buf, 0, 0, 3, ' ', tabwriter.FilterHTML)
+	fmt.Fprintln(writer, "LAST SEEN\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for _, event := range items {
+		object := fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name)
+		fmt.Fprintln(writer, fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s",
+			event.LastTimestamp.UTC(), event.Type, event.Reason, object, event.Count, event.Message))
+	}
+	writer.Flush()
+	return ioutil.WriteFile(file, buf.Bytes(), 0644)
+}
+
+// capturePods writes a description of each pod and the logs of each of its containers
+func (n *Runner) capturePods(dest string, errs io.Writer) error {
+	pods, err := n.clientset.CoreV1().Pods(n.Namespace()).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	describeDir := filepath.Join(dest, "pods")
+	if err := os.MkdirAll(describeDir, 0755); err != nil {
+		return err
+	}
+
+	describer := &versioned.PodDescriber{Interface: n.clientset}
+	for _, pod := range pods.Items {
+		description, err := describer.Describe(n.Namespace(), pod.Name, describe.DescriberSettings{ShowEvents: true})
+		if err != nil {
+			fmt.Fprintf(errs, "pods/%s: %v\n", pod.Name, err)
+		} else if err := ioutil.WriteFile(filepath.Join(describeDir, pod.Name+".txt"), []byte(description), 0644); err != nil {
+			return err
+		}
+
+		logDir := filepath.Join(dest, "logs", pod.Name)
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			return err
+		}
+
+		statuses := make(map[string]corev1.ContainerStatus)
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			statuses[status.Name] = status
+		}
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			status, ok := statuses[container.Name]
+			if !ok {
+				continue
+			}
+			if status.State.Waiting == nil || status.RestartCount > 0 {
+				file := filepath.Join(logDir, container.Name+".log")
+				if err := n.captureLogs(pod.Name, container.Name, false, file); err != nil {
+					fmt.Fprintf(errs, "logs/%s/%s: %v\n", pod.Name, container.Name, err)
+				}
+			}
+			if status.RestartCount > 0 {
+				file := filepath.Join(logDir, container.Name+".previous.log")
+				if err := n.captureLogs(pod.Name, container.Name, true, file); err != nil {
+					fmt.Fprintf(errs, "logs/%s/%s (previous): %v\n", pod.Name, container.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// captureLogs writes the logs for the given container to a file
+func (n *Runner) captureLogs(pod, container string, previous bool, file string) error {
+	req := n.clientset.CoreV1().Pods(n.Namespace()).GetLogs(pod, &corev1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	})
+	reader, err := req.Stream()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, reader)
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedactSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name": "test",
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": "{\"data\":{\"password\":\"c2VjcmV0\"}}",
+				},
+			},
+			"data": map[string]interface{}{
+				"password": "c2VjcmV0",
+			},
+		},
+	}
+	redactSecret(secret)
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	assert.Equal(t, redacted, data["password"])
+	assert.Equal(t, redacted, secret.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"])
+	assert.Equal(t, "test", secret.GetName())
+}
+
+func TestPrintDiagnostics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	out := &bytes.Buffer{}
+	PrintDiagnostics(out, dir)
+	assert.Empty(t, out.String())
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "suite", DiagnosticsDir, "releases"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "other", "logs"), 0755))
+	PrintDiagnostics(out, dir)
+	assert.Equal(t, "Diagnostics were captured to:\n  "+filepath.Join(dir, "suite", DiagnosticsDir)+"\n", out.String())
+}
@@ -0,0 +1,107 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"strings"
+	"time"
+)
+
+// Phase is a job lifecycle phase
+type Phase string
+
+const (
+	// PhaseRunning is the phase in which the job container has started running
+	PhaseRunning Phase = "Running"
+	// PhaseReady is the phase in which the job container has passed its readiness probe
+	PhaseReady Phase = "Ready"
+	// PhaseComplete is the phase in which the job container has terminated
+	PhaseComplete Phase = "Complete"
+)
+
+// TimeoutError is returned when a job does not reach a phase before its timeout
+type TimeoutError struct {
+	// Job is the job ID
+	Job string
+	// Phase is the phase the job was waiting to reach
+	Phase Phase
+	// Timeout is the job timeout
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("job %s timed out after %s waiting for phase %s", e.Job, e.Timeout, e.Phase)
+}
+
+// IsTimeout returns whether the given error is a job timeout
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// PodError is returned when a job pod fails in a way from which it cannot recover
+type PodError struct {
+	// Job is the job ID
+	Job string
+	// Pod is the name of the failed pod
+	Pod string
+	// Phase is the phase the job was waiting to reach
+	Phase Phase
+	// Reason is the reason for the failure
+	Reason string
+	// Message is a human readable description of the failure
+	Message string
+	// Containers is the state of the pod's containers at the time of the failure
+	Containers []corev1.ContainerStatus
+	// Events is the list of recent events for the pod
+	Events []corev1.Event
+}
+
+func (e *PodError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "job %s failed waiting for phase %s: pod %s: %s", e.Job, e.Phase, e.Pod, e.Reason)
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	for _, status := range e.Containers {
+		fmt.Fprintf(&b, "\n  container %s: %s", status.Name, formatContainerState(status.State))
+		if status.LastTerminationState.Terminated != nil {
+			fmt.Fprintf(&b, " (last %s)", formatContainerState(status.LastTerminationState))
+		}
+	}
+	if len(e.Events) > 0 {
+		fmt.Fprint(&b, "\n  events:")
+		for _, event := range e.Events {
+			fmt.Fprintf(&b, "\n    %s %s %s: %s", event.LastTimestamp.Format(time.RFC3339), event.Type, event.Reason, event.Message)
+		}
+	}
+	return b.String()
+}
+
+// formatContainerState returns a human readable description of the given container state
+func formatContainerState(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("waiting: %s %s", state.Waiting.Reason, state.Waiting.Message)
+	case state.Running != nil:
+		return fmt.Sprintf("running since %s", state.Running.StartedAt.Format(time.RFC3339))
+	case state.Terminated != nil:
+		return fmt.Sprintf("terminated: %s (exit code %d) %s", state.Terminated.Reason, state.Terminated.ExitCode, state.Terminated.Message)
+	}
+	return "unknown"
+}
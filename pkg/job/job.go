@@ -27,6 +27,21 @@ const configPath = "/etc/helmit"
 const configFile = "job.json"
 const readyFile = "/tmp/job-ready"
 
+// ArtifactsPath is the directory in job pods to which suites can write artifacts to be copied back to the client
+const ArtifactsPath = "/tmp/helmit/artifacts"
+
+// HelmDriver is the storage driver with which Helm records the releases installed by a job
+type HelmDriver string
+
+const (
+	// MemoryDriver records releases in the job's memory
+	MemoryDriver HelmDriver = "memory"
+	// SecretDriver records releases in secrets in the release namespace
+	SecretDriver HelmDriver = "secret"
+	// ConfigMapDriver records releases in config maps in the release namespace
+	ConfigMapDriver HelmDriver = "configmap"
+)
+
 // Config is a job configuration
 type Config struct {
 	ID              string
@@ -36,9 +51,16 @@ type Config struct {
 	Context         string
 	Values          map[string][]string
 	ValueFiles      map[string][]string
+	StrictValues    bool
+	HelmDriver      HelmDriver
 	Args            []string
 	Env             map[string]string
 	Timeout         time.Duration
+	PodTemplate     *corev1.PodTemplateSpec
+	RBAC            *RBAC
+	NoTeardown      bool
+	KeepOnFailure   bool
+	ArtifactsDir    string
 }
 
 // Job is a job configuration
@@ -46,6 +68,7 @@ type Job struct {
 	*Config
 	JobConfig interface{}
 	Type      string
+	deadline  time.Time
 }
 
 // Bootstrap bootstraps the job
@@ -0,0 +1,599 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/util/logging"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"strings"
+)
+
+const clusterRole = "kube-test-cluster"
+
+// RBACScope is the scope of the roles bound to job service accounts
+type RBACScope string
+
+const (
+	// ClusterScope binds job service accounts to a ClusterRole
+	ClusterScope RBACScope = "cluster"
+	// NamespaceScope binds job service accounts to a Role in the job namespace
+	NamespaceScope RBACScope = "namespace"
+)
+
+// RBAC is the role based access control configuration for a job
+type RBAC struct {
+	// Scope is the scope of the roles bound to job service accounts
+	Scope RBACScope `json:"scope,omitempty"`
+	// Rules is the list of rules required by the job
+	// If no rules are configured, jobs are granted the default set of rules.
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+}
+
+// PolicyRuleProvider is implemented by suites that require access to additional resources
+type PolicyRuleProvider interface {
+	// PolicyRules returns the RBAC rules required by the suite
+	PolicyRules() []rbacv1.PolicyRule
+}
+
+// WithSuite returns a copy of the RBAC configuration including any rules required by the given suite
+func (r *RBAC) WithSuite(suite interface{}) *RBAC {
+	rbac := &RBAC{}
+	if r != nil {
+		rbac.Scope = r.Scope
+		rbac.Rules = append(rbac.Rules, r.Rules...)
+	}
+	if provider, ok := suite.(PolicyRuleProvider); ok {
+		rbac.Rules = append(rbac.Rules, provider.PolicyRules()...)
+	}
+	return rbac
+}
+
+// defaultRules is the set of rules granted to jobs that don't configure their own rules
+var defaultRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{
+			"pods",
+			"pods/log",
+			"pods/exec",
+			"services",
+			"endpoints",
+			"persistentvolumeclaims",
+			"events",
+			"configmaps",
+			"secrets",
+			"serviceaccounts",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{
+			"deployments",
+			"daemonsets",
+			"replicasets",
+			"statefulsets",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{"policy"},
+		Resources: []string{"poddisruptionbudgets"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{"batch"},
+		Resources: []string{"jobs"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{
+			"roles",
+			"rolebindings",
+			"clusterroles",
+			"clusterrolebindings",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{"apiextensions.k8s.io"},
+		Resources: []string{"customresourcedefinitions"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"nodes"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+}
+
+// coordinatorRules is the set of rules required by coordinators to manage jobs and their namespaces
+// Coordinators delete the objects left by suites when resetting a namespace for another suite.
+var coordinatorRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{
+			"namespaces",
+			"pods",
+			"pods/log",
+			"pods/exec",
+			"services",
+			"persistentvolumeclaims",
+			"configmaps",
+			"secrets",
+			"serviceaccounts",
+			"events",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{
+			"deployments",
+			"daemonsets",
+			"replicasets",
+			"statefulsets",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{"policy"},
+		Resources: []string{"poddisruptionbudgets"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{"batch"},
+		Resources: []string{"jobs"},
+		Verbs:     []string{"*"},
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{
+			"roles",
+			"rolebindings",
+			"clusterroles",
+			"clusterrolebindings",
+		},
+		Verbs: []string{"*"},
+	},
+}
+
+// workerRules is the set of rules required by workers to run within their namespace
+// Workers install charts, which routinely include workloads and volume claims, and read nodes and storage classes to
+// check the cluster meets the requirements of suites.
+var workerRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{
+			"pods",
+			"pods/log",
+			"pods/exec",
+			"services",
+			"endpoints",
+			"persistentvolumeclaims",
+			"configmaps",
+			"secrets",
+			"serviceaccounts",
+			"events",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{
+			"deployments",
+			"daemonsets",
+			"replicasets",
+			"statefulsets",
+		},
+		Verbs: []string{"*"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"nodes"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+}
+
+// getRules returns the rules to grant to the namespace's service account
+func (n *Runner) getRules() []rbacv1.PolicyRule {
+	if len(n.rbac.Rules) == 0 {
+		return defaultRules
+	}
+	var rules []rbacv1.PolicyRule
+	if n.server {
+		rules = append(rules, workerRules...)
+	} else {
+		rules = append(rules, coordinatorRules...)
+	}
+	return append(rules, n.rbac.Rules...)
+}
+
+// clusterResources is the set of cluster-scoped resources in the rules granted by Helmit, keyed by API group
+var clusterResources = map[string][]string{
+	"": {
+		"namespaces",
+		"nodes",
+		"persistentvolumes",
+	},
+	"rbac.authorization.k8s.io": {
+		"clusterroles",
+		"clusterrolebindings",
+	},
+	"storage.k8s.io": {
+		"storageclasses",
+	},
+	"apiextensions.k8s.io": {
+		"customresourcedefinitions",
+	},
+	"scheduling.k8s.io": {
+		"priorityclasses",
+	},
+}
+
+// isClusterResource returns whether the given resource in the given API group is cluster-scoped
+func isClusterResource(group, resource string) bool {
+	resource = strings.Split(resource, "/")[0]
+	for _, clusterResource := range clusterResources[group] {
+		if resource == clusterResource {
+			return true
+		}
+	}
+	return false
+}
+
+// getNamespaceRules returns the given rules without the cluster-scoped resources a Role cannot grant
+// Resources are removed from a rule only if they're cluster-scoped in every group the rule applies to, and rules
+// left with no resources are dropped.
+func getNamespaceRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	namespaceRules := make([]rbacv1.PolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			continue
+		}
+		resources := make([]string, 0, len(rule.Resources))
+		for _, resource := range rule.Resources {
+			clusterScoped := len(rule.APIGroups) > 0
+			for _, group := range rule.APIGroups {
+				clusterScoped = clusterScoped && isClusterResource(group, resource)
+			}
+			if !clusterScoped {
+				resources = append(resources, resource)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		rule.Resources = resources
+		namespaceRules = append(namespaceRules, rule)
+	}
+	return namespaceRules
+}
+
+// getRoleName returns the name of the role to bind to the namespace's service account
+// Namespaces using the default rules share the default role. Otherwise, roles are named for a hash of
+// their rules, so namespaces requiring the same rules share the same role.
+func (n *Runner) getRoleName() (string, error) {
+	if len(n.rbac.Rules) == 0 {
+		return clusterRole, nil
+	}
+	bytes, err := json.Marshal(n.getRules())
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(bytes)
+	return fmt.Sprintf("%s-%x", clusterRole, hash[:4]), nil
+}
+
+// setupRBAC sets up role based access controls for the cluster
+func (n *Runner) setupRBAC() error {
+	step := logging.NewStep(n.Namespace(), "Set up RBAC")
+	step.Start()
+	roleName, err := n.getRoleName()
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+	if n.rbac.Scope == NamespaceScope {
+		if err := n.createRole(roleName); err != nil {
+			step.Fail(err)
+			return err
+		}
+		if err := n.createRoleBinding(roleName); err != nil {
+			step.Fail(err)
+			return err
+		}
+	} else {
+		if err := n.bindClusterRole(roleName); err != nil {
+			step.Fail(err)
+			return err
+		}
+	}
+	if err := n.createServiceAccount(); err != nil {
+		step.Fail(err)
+		return err
+	}
+	step.Complete()
+	return nil
+}
+
+// bindClusterRole creates the ClusterRole and binds it to the namespace's service account
+// Roles for custom rules are deleted by the teardown of the last namespace bound to them, which may run concurrently,
+// so the role is created again if it was deleted before it was bound.
+func (n *Runner) bindClusterRole(name string) error {
+	if err := n.createClusterRole(name); err != nil {
+		return err
+	}
+	if err := n.createClusterRoleBinding(name); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return n.bindClusterRole(name)
+		}
+		return err
+	}
+	return n.createClusterRole(name)
+}
+
+// createClusterRole creates the ClusterRole required by the tests if not yet created
+func (n *Runner) createClusterRole(name string) error {
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Rules: n.getRules(),
+	}
+	_, err := n.clientset.RbacV1().ClusterRoles().Create(role)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// createClusterRoleBinding creates the ClusterRoleBinding required by the test manager
+func (n *Runner) createClusterRoleBinding(name string) error {
+	roleBinding, err := n.clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		roleBinding = &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      "ServiceAccount",
+					Name:      n.Namespace(),
+					Namespace: n.Namespace(),
+				},
+			},
+			RoleRef: rbacv1.RoleRef{
+				Kind:     "ClusterRole",
+				Name:     name,
+				APIGroup: "rbac.authorization.k8s.io",
+			},
+		}
+		_, err := n.clientset.RbacV1().ClusterRoleBindings().Create(roleBinding)
+		if err != nil && k8serrors.IsAlreadyExists(err) {
+			return n.createClusterRoleBinding(name)
+		}
+		return err
+	}
+
+	subject := rbacv1.Subject{
+		Kind:      "ServiceAccount",
+		Name:      n.Namespace(),
+		Namespace: n.Namespace(),
+	}
+	for _, existing := range roleBinding.Subjects {
+		if existing == subject {
+			return nil
+		}
+	}
+	roleBinding.Subjects = append(roleBinding.Subjects, subject)
+	_, err = n.clientset.RbacV1().ClusterRoleBindings().Update(roleBinding)
+	if err != nil && k8serrors.IsConflict(err) {
+		return n.createClusterRoleBinding(name)
+	}
+	return err
+}
+
+// createRole creates the Role required by the tests in the namespace if not yet created
+// Cluster-scoped resources cannot be granted by a Role, so they're omitted from its rules.
+func (n *Runner) createRole(name string) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: n.Namespace(),
+		},
+		Rules: getNamespaceRules(n.getRules()),
+	}
+	_, err := n.clientset.RbacV1().Roles(n.Namespace()).Create(role)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// createRoleBinding creates the RoleBinding required by the test manager in the namespace
+func (n *Runner) createRoleBinding(name string) error {
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: n.Namespace(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      n.Namespace(),
+				Namespace: n.Namespace(),
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     name,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+	}
+	_, err := n.clientset.RbacV1().RoleBindings(n.Namespace()).Create(roleBinding)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// createServiceAccount creates a ServiceAccount used by the test manager
+func (n *Runner) createServiceAccount() error {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Namespace(),
+			Namespace: n.Namespace(),
+		},
+	}
+	_, err := n.clientset.CoreV1().ServiceAccounts(n.Namespace()).Create(serviceAccount)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// teardownRBAC removes the namespace's service account from the roles granted to it
+// Namespaced roles are deleted along with the namespace, so only cluster role bindings must be updated. Roles for
+// custom rules are deleted once no namespace is bound to them.
+func (n *Runner) teardownRBAC() error {
+	if n.rbac.Scope == NamespaceScope {
+		return nil
+	}
+	roleName, err := n.getRoleName()
+	if err != nil {
+		return err
+	}
+	return removeClusterRoleBindingSubjects(n.clientset, roleName, func(subject rbacv1.Subject) bool {
+		return subject.Kind == "ServiceAccount" && subject.Namespace == n.Namespace()
+	})
+}
+
+// isCustomRoleName returns whether the named role was created for a custom set of rules
+func isCustomRoleName(name string) bool {
+	return strings.HasPrefix(name, clusterRole+"-")
+}
+
+// removeClusterRoleBindingSubjects removes the subjects matching the given predicate from a ClusterRoleBinding
+// If no subjects remain in the binding of a role for custom rules, the binding and role are deleted.
+func removeClusterRoleBindingSubjects(client k8s.Interface, name string, predicate func(subject rbacv1.Subject) bool) error {
+	roleBinding, err := client.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	subjects := make([]rbacv1.Subject, 0, len(roleBinding.Subjects))
+	for _, subject := range roleBinding.Subjects {
+		if !predicate(subject) {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) == len(roleBinding.Subjects) {
+		return nil
+	}
+
+	roleBinding.Subjects = subjects
+	roleBinding, err = client.RbacV1().ClusterRoleBindings().Update(roleBinding)
+	if err != nil {
+		if k8serrors.IsConflict(err) {
+			return removeClusterRoleBindingSubjects(client, name, predicate)
+		}
+		return err
+	}
+	if len(roleBinding.Subjects) == 0 && isCustomRoleName(name) {
+		return deleteClusterRole(client, roleBinding)
+	}
+	return nil
+}
+
+// deleteClusterRole deletes an unused ClusterRoleBinding and the ClusterRole it binds
+// The binding is only deleted if it's unchanged, so a namespace bound to the role concurrently retains it. If the
+// role is bound again after the binding is deleted, the role is recreated.
+func deleteClusterRole(client k8s.Interface, roleBinding *rbacv1.ClusterRoleBinding) error {
+	role, err := client.RbacV1().ClusterRoles().Get(roleBinding.RoleRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		role = nil
+	}
+
+	err = client.RbacV1().ClusterRoleBindings().Delete(roleBinding.Name, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			ResourceVersion: &roleBinding.ResourceVersion,
+		},
+	})
+	if err != nil {
+		if k8serrors.IsConflict(err) || k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if role == nil {
+		return nil
+	}
+
+	err = client.RbacV1().ClusterRoles().Delete(role.Name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	_, err = client.RbacV1().ClusterRoleBindings().Get(roleBinding.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = client.RbacV1().ClusterRoles().Create(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: role.Name,
+		},
+		Rules: role.Rules,
+	})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
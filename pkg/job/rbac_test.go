@@ -0,0 +1,274 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/onosproject/helmit/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+)
+
+// testClient is a client for the namespace of a runner backed by a fake clientset
+type testClient struct {
+	kubernetes.Client
+	namespace string
+}
+
+func (c *testClient) Namespace() string {
+	return c.namespace
+}
+
+// newTestRunner returns a worker runner for the given namespace using the given clientset
+func newTestRunner(clientset k8s.Interface, namespace string, rbac *RBAC) *Runner {
+	return &Runner{
+		Client:    &testClient{namespace: namespace},
+		clientset: clientset,
+		server:    true,
+		rbac:      rbac,
+		cancelCh:  make(chan struct{}),
+	}
+}
+
+// testSuite is a suite that requires access to additional resources
+type testSuite struct{}
+
+func (s testSuite) PolicyRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"statefulsets"},
+			Verbs:     []string{"get"},
+		},
+	}
+}
+
+func TestWithSuite(t *testing.T) {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"*"},
+		},
+	}
+	rbac := &RBAC{Scope: NamespaceScope, Rules: rules}
+	merged := rbac.WithSuite(testSuite{})
+	assert.Equal(t, NamespaceScope, merged.Scope)
+	assert.Equal(t, append(rules, testSuite{}.PolicyRules()...), merged.Rules)
+	assert.Len(t, rbac.Rules, 1)
+	assert.Equal(t, rules, rbac.WithSuite(struct{}{}).Rules)
+
+	var empty *RBAC
+	assert.Equal(t, &RBAC{}, empty.WithSuite(struct{}{}))
+	assert.Equal(t, testSuite{}.PolicyRules(), empty.WithSuite(testSuite{}).Rules)
+}
+
+func TestGetRoleName(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	name, err := newTestRunner(clientset, "test-1", &RBAC{}).getRoleName()
+	assert.NoError(t, err)
+	assert.Equal(t, clusterRole, name)
+
+	rbac := (&RBAC{}).WithSuite(testSuite{})
+	name1, err := newTestRunner(clientset, "test-1", rbac).getRoleName()
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+clusterRole+"-[0-9a-f]{8}$", name1)
+	name2, err := newTestRunner(clientset, "test-2", rbac).getRoleName()
+	assert.NoError(t, err)
+	assert.Equal(t, name1, name2)
+
+	coordinator := newTestRunner(clientset, "test-3", rbac)
+	coordinator.server = false
+	name3, err := coordinator.getRoleName()
+	assert.NoError(t, err)
+	assert.NotEqual(t, name1, name3)
+}
+
+func TestClusterScopeRBAC(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	rbac := (&RBAC{Scope: ClusterScope}).WithSuite(testSuite{})
+	runner1 := newTestRunner(clientset, "test-1", rbac)
+	runner2 := newTestRunner(clientset, "test-2", rbac)
+	assert.NoError(t, runner1.setupRBAC())
+	assert.NoError(t, runner2.setupRBAC())
+	assert.NoError(t, runner1.setupRBAC())
+
+	name, err := runner1.getRoleName()
+	assert.NoError(t, err)
+	role, err := clientset.RbacV1().ClusterRoles().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, runner1.getRules(), role.Rules)
+
+	// Namespaces requiring the same rules share a binding with one subject per namespace
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, name, binding.RoleRef.Name)
+	assert.Equal(t, "ClusterRole", binding.RoleRef.Kind)
+	assert.Len(t, binding.Subjects, 2)
+	assert.Equal(t, "test-1", binding.Subjects[0].Namespace)
+	assert.Equal(t, "test-2", binding.Subjects[1].Namespace)
+
+	_, err = clientset.CoreV1().ServiceAccounts("test-1").Get("test-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	roles, err := clientset.RbacV1().Roles("test-1").List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, roles.Items, 0)
+
+	assert.NoError(t, runner1.teardownRBAC())
+	binding, err = clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, binding.Subjects, 1)
+	assert.Equal(t, "test-2", binding.Subjects[0].Namespace)
+
+	// The role for the custom rules is deleted with its binding once no namespace is bound to it
+	assert.NoError(t, runner2.teardownRBAC())
+	_, err = clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+	_, err = clientset.RbacV1().ClusterRoles().Get(name, metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	// The default role is shared by all namespaces and retained
+	runner3 := newTestRunner(clientset, "test-3", &RBAC{})
+	assert.NoError(t, runner3.setupRBAC())
+	assert.NoError(t, runner3.teardownRBAC())
+	binding, err = clientset.RbacV1().ClusterRoleBindings().Get(clusterRole, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, binding.Subjects, 0)
+	_, err = clientset.RbacV1().ClusterRoles().Get(clusterRole, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDeleteClusterRoleRebound(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	rbac := (&RBAC{Scope: ClusterScope}).WithSuite(testSuite{})
+	runner := newTestRunner(clientset, "test-1", rbac)
+	assert.NoError(t, runner.setupRBAC())
+	name, err := runner.getRoleName()
+	assert.NoError(t, err)
+
+	// A namespace bound to the role after its binding was deleted keeps the role
+	deleted := false
+	clientset.PrependReactor("delete", "clusterroles", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if !deleted {
+			deleted = true
+			assert.NoError(t, clientset.Tracker().Add(&rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Subjects:   []rbacv1.Subject{newServiceAccountSubject("test-2")},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: name},
+			}))
+		}
+		return false, nil, nil
+	})
+	assert.NoError(t, runner.teardownRBAC())
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "test-2", binding.Subjects[0].Namespace)
+	role, err := clientset.RbacV1().ClusterRoles().Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, runner.getRules(), role.Rules)
+}
+
+func TestNamespaceScopeRBAC(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	runner := newTestRunner(clientset, "test-1", (&RBAC{Scope: NamespaceScope}).WithSuite(testSuite{}))
+	assert.NoError(t, runner.setupRBAC())
+	assert.NoError(t, runner.setupRBAC())
+
+	name, err := runner.getRoleName()
+	assert.NoError(t, err)
+	role, err := clientset.RbacV1().Roles("test-1").Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, getNamespaceRules(runner.getRules()), role.Rules)
+	for _, rule := range role.Rules {
+		assert.NotContains(t, rule.Resources, "nodes")
+		assert.NotContains(t, rule.Resources, "storageclasses")
+	}
+	binding, err := clientset.RbacV1().RoleBindings("test-1").Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Role", binding.RoleRef.Kind)
+	assert.Equal(t, "test-1", binding.Subjects[0].Namespace)
+	_, err = clientset.CoreV1().ServiceAccounts("test-1").Get("test-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// No cluster-wide resources are created or modified in namespace scope
+	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, clusterRoles.Items, 0)
+	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, clusterRoleBindings.Items, 0)
+	assert.NoError(t, runner.teardownRBAC())
+}
+
+func TestGetNamespaceRules(t *testing.T) {
+	rules := getNamespaceRules([]rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"namespaces", "pods", "nodes/proxy"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"clusterroles", "clusterrolebindings"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"", "storage.k8s.io"},
+			Resources: []string{"storageclasses"},
+			Verbs:     []string{"get"},
+		},
+		{
+			APIGroups: []string{"example.com"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+		{
+			NonResourceURLs: []string{"/metrics"},
+			Verbs:           []string{"get"},
+		},
+	})
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{"", "storage.k8s.io"},
+			Resources: []string{"storageclasses"},
+			Verbs:     []string{"get"},
+		},
+		{
+			APIGroups: []string{"example.com"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+	}, rules)
+
+	// Worker roles in namespace scope retain the namespaced resources charts require
+	runner := newTestRunner(fake.NewSimpleClientset(), "test-1", (&RBAC{Scope: NamespaceScope}).WithSuite(testSuite{}))
+	rules = getNamespaceRules(runner.getRules())
+	assert.Contains(t, rules[0].Resources, "persistentvolumeclaims")
+	assert.Equal(t, []string{"apps"}, rules[1].APIGroups)
+	for _, rule := range rules {
+		assert.NotContains(t, rule.APIGroups, "storage.k8s.io")
+	}
+}
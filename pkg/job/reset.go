@@ -0,0 +1,309 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/onosproject/helmit/pkg/util/logging"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8s "k8s.io/client-go/kubernetes"
+	"time"
+)
+
+// resetTimeout is the time to wait for the pods and volume claims of a reset namespace to be deleted
+const resetTimeout = 5 * time.Minute
+
+// rootCAConfigMap is the ConfigMap Kubernetes publishes to every namespace
+const rootCAConfigMap = "kube-root-ca.crt"
+
+// namespacedResource is a kind of namespaced object deleted when a namespace is reset
+type namespacedResource struct {
+	list   func(options metav1.ListOptions) ([]metav1.Object, error)
+	delete func(name string, options *metav1.DeleteOptions) error
+}
+
+// ResetNamespace deletes the objects left in the namespace by the jobs run in it
+// Helm releases stored in the namespace and the objects installed by them are deleted, so another suite can install
+// the same releases. The namespace's service account and RBAC objects are retained. Cluster-scoped objects and
+// custom resources are not deleted.
+func (n *Runner) ResetNamespace() error {
+	step := logging.NewStep(n.Namespace(), "Reset namespace %s", n.Namespace())
+	step.Start()
+	roleName, err := n.getRoleName()
+	if err != nil {
+		step.Fail(err)
+		return err
+	}
+	if err := resetNamespace(n.clientset, n.Namespace(), roleName); err != nil {
+		step.Fail(err)
+		return err
+	}
+	step.Complete()
+	return nil
+}
+
+// resetNamespace deletes the objects in the namespace other than those required to run jobs in it
+func resetNamespace(client k8s.Interface, namespace, roleName string) error {
+	retained := func(object metav1.Object) bool {
+		switch o := object.(type) {
+		case *corev1.ServiceAccount:
+			return o.Name == namespace || o.Name == "default"
+		case *corev1.Secret:
+			return o.Type == corev1.SecretTypeServiceAccountToken
+		case *corev1.ConfigMap:
+			return o.Name == rootCAConfigMap
+		}
+		return false
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	options := &metav1.DeleteOptions{PropagationPolicy: &propagation}
+	for _, resource := range getNamespacedResources(client, namespace, roleName) {
+		objects, err := resource.list(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if retained(object) {
+				continue
+			}
+			if err := resource.delete(object.GetName(), options); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	// Wait for pods and volume claims to be deleted so their names and volumes can be reused
+	return wait.PollImmediate(time.Second, resetTimeout, func() (bool, error) {
+		pods, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		claims, err := client.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(pods.Items) == 0 && len(claims.Items) == 0, nil
+	})
+}
+
+// getNamespacedResources returns the kinds of objects deleted when the namespace is reset
+// Controllers are listed before the objects they manage to avoid recreating deleted objects.
+func getNamespacedResources(client k8s.Interface, namespace, roleName string) []namespacedResource {
+	return []namespacedResource{
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.AppsV1().Deployments(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.AppsV1().Deployments(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.AppsV1().StatefulSets(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.AppsV1().StatefulSets(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.AppsV1().DaemonSets(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.AppsV1().DaemonSets(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.AppsV1().ReplicaSets(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.AppsV1().ReplicaSets(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.BatchV1().Jobs(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.BatchV1().Jobs(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().Pods(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().Pods(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().Services(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().Services(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().PersistentVolumeClaims(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().PersistentVolumeClaims(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().ConfigMaps(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().ConfigMaps(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().Secrets(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().Secrets(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.CoreV1().ServiceAccounts(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.CoreV1().ServiceAccounts(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.PolicyV1beta1().PodDisruptionBudgets(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, len(list.Items))
+				for i := range list.Items {
+					objects[i] = &list.Items[i]
+				}
+				return objects, nil
+			},
+			delete: client.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.RbacV1().RoleBindings(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, 0, len(list.Items))
+				for i := range list.Items {
+					if list.Items[i].Name != roleName {
+						objects = append(objects, &list.Items[i])
+					}
+				}
+				return objects, nil
+			},
+			delete: client.RbacV1().RoleBindings(namespace).Delete,
+		},
+		{
+			list: func(options metav1.ListOptions) ([]metav1.Object, error) {
+				list, err := client.RbacV1().Roles(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				objects := make([]metav1.Object, 0, len(list.Items))
+				for i := range list.Items {
+					if list.Items[i].Name != roleName {
+						objects = append(objects, &list.Items[i])
+					}
+				}
+				return objects, nil
+			},
+			delete: client.RbacV1().Roles(namespace).Delete,
+		},
+	}
+}
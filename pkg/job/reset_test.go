@@ -0,0 +1,87 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func TestResetNamespace(t *testing.T) {
+	namespace := "test-1-shard-1"
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{ObjectMeta: meta("raft")},
+		&corev1.Pod{ObjectMeta: meta("raft-0")},
+		&corev1.Service{ObjectMeta: meta("raft")},
+		&corev1.PersistentVolumeClaim{ObjectMeta: meta("data-raft-0")},
+		&corev1.ConfigMap{ObjectMeta: meta("raft-config")},
+		&corev1.ConfigMap{ObjectMeta: meta(rootCAConfigMap)},
+		&corev1.Secret{ObjectMeta: meta("sh.helm.release.v1.raft.v1")},
+		&corev1.Secret{ObjectMeta: meta("test-1-shard-1-token"), Type: corev1.SecretTypeServiceAccountToken},
+		&corev1.ServiceAccount{ObjectMeta: meta(namespace)},
+		&corev1.ServiceAccount{ObjectMeta: meta("default")},
+		&corev1.ServiceAccount{ObjectMeta: meta("raft")},
+		&rbacv1.Role{ObjectMeta: meta(clusterRole)},
+		&rbacv1.RoleBinding{ObjectMeta: meta(clusterRole)},
+		&rbacv1.RoleBinding{ObjectMeta: meta("raft")},
+	)
+	runner := newTestRunner(clientset, namespace, &RBAC{Scope: NamespaceScope})
+	assert.NoError(t, runner.ResetNamespace())
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, statefulSets.Items, 0)
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, pods.Items, 0)
+	services, err := clientset.CoreV1().Services(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, services.Items, 0)
+	claims, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, claims.Items, 0)
+
+	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, configMaps.Items, 1)
+	assert.Equal(t, rootCAConfigMap, configMaps.Items[0].Name)
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, secrets.Items, 1)
+	assert.Equal(t, corev1.SecretTypeServiceAccountToken, secrets.Items[0].Type)
+
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	names := make([]string, len(serviceAccounts.Items))
+	for i, serviceAccount := range serviceAccounts.Items {
+		names[i] = serviceAccount.Name
+	}
+	assert.ElementsMatch(t, []string{namespace, "default"}, names)
+
+	_, err = clientset.RbacV1().Roles(namespace).Get(clusterRole, metav1.GetOptions{})
+	assert.NoError(t, err)
+	roleBindings, err := clientset.RbacV1().RoleBindings(namespace).List(metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, roleBindings.Items, 1)
+	assert.Equal(t, clusterRole, roleBindings.Items[0].Name)
+}
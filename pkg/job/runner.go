@@ -23,34 +23,53 @@ import (
 	"github.com/onosproject/helmit/pkg/util/logging"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/watch"
+	k8s "k8s.io/client-go/kubernetes"
 	"path"
+	"sort"
+	"sync"
 	"time"
 )
 
-const clusterRole = "kube-test-cluster"
+const jobContainer = "job"
+const artifactsContainer = "artifacts"
+const artifactsDoneFile = "/tmp/artifacts-done"
 
 // NewNamespace returns a new job namespace
-func NewNamespace(namespace string) *Runner {
-	return newRunner(namespace, true)
+func NewNamespace(namespace string, rbac *RBAC) *Runner {
+	return newRunner(namespace, true, rbac)
 }
 
 // newRunner returns a new job runner
-func newRunner(namespace string, server bool) *Runner {
+func newRunner(namespace string, server bool, rbac *RBAC) *Runner {
+	if rbac == nil {
+		rbac = &RBAC{}
+	}
+	client := kubernetes.NewForNamespaceOrDie(namespace)
 	return &Runner{
-		Client: kubernetes.NewForNamespaceOrDie(namespace),
-		server: server,
+		Client:    client,
+		clientset: client.Clientset(),
+		server:    server,
+		rbac:      rbac,
+		cancelCh:  make(chan struct{}),
 	}
 }
 
 // Runner manages test jobs within a namespace
 type Runner struct {
 	kubernetes.Client
-	server bool
+	clientset  k8s.Interface
+	server     bool
+	rbac       *RBAC
+	pods       *podWatcher
+	mu         sync.Mutex
+	cancelCh   chan struct{}
+	cancelOnce sync.Once
 }
 
 // Run runs the given job
@@ -72,17 +91,17 @@ func (n *Runner) StartJob(job *Job) error {
 
 // streamLogs streams logs from the given pod
 func (n *Runner) streamLogs(job *Job) {
-	// Get the stream of logs for the pod
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return len(pod.Status.ContainerStatuses) > 0 &&
-			pod.Status.ContainerStatuses[0].Ready
+	// Get the stream of logs for the pod once it's ready or has already exited
+	pod, err := n.awaitPod(job, PhaseReady, func(pod *corev1.Pod) bool {
+		status := getJobContainerStatus(pod)
+		return status != nil && (status.Ready || status.State.Terminated != nil)
 	})
-	if err != nil || pod == nil {
+	if err != nil {
 		return
 	}
 
-	req := n.Clientset().CoreV1().Pods(n.Namespace()).GetLogs(pod.Name, &corev1.PodLogOptions{
-		Container: "job",
+	req := n.clientset.CoreV1().Pods(n.Namespace()).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: jobContainer,
 		Follow:    true,
 	})
 	reader, err := req.Stream()
@@ -130,224 +149,26 @@ func (n *Runner) setupNamespace() error {
 	}
 	step := logging.NewStep(n.Namespace(), "Setup namespace")
 	step.Start()
-	_, err := n.Clientset().CoreV1().Namespaces().Create(ns)
+	_, err := n.clientset.CoreV1().Namespaces().Create(ns)
 	if err != nil && !k8serrors.IsAlreadyExists(err) {
 		return err
 	}
 	return n.setupRBAC()
 }
 
-// setupRBAC sets up role based access controls for the cluster
-func (n *Runner) setupRBAC() error {
-	step := logging.NewStep(n.Namespace(), "Set up RBAC")
-	step.Start()
-	if err := n.createClusterRole(); err != nil {
-		step.Fail(err)
-		return err
-	}
-	if err := n.createClusterRoleBinding(); err != nil {
-		step.Fail(err)
-		return err
-	}
-	if err := n.createServiceAccount(); err != nil {
-		step.Fail(err)
-		return err
-	}
-	step.Complete()
-	return nil
-}
-
-// createClusterRole creates the ClusterRole required by the Atomix controller and tests if not yet created
-func (n *Runner) createClusterRole() error {
-	role := &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: clusterRole,
-		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{
-					"",
-				},
-				Resources: []string{
-					"pods",
-					"pods/log",
-					"pods/exec",
-					"services",
-					"endpoints",
-					"persistentvolumeclaims",
-					"events",
-					"configmaps",
-					"secrets",
-					"serviceaccounts",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"",
-				},
-				Resources: []string{
-					"namespaces",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"apps",
-				},
-				Resources: []string{
-					"deployments",
-					"daemonsets",
-					"replicasets",
-					"statefulsets",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"policy",
-				},
-				Resources: []string{
-					"poddisruptionbudgets",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"batch",
-				},
-				Resources: []string{
-					"jobs",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"rbac.authorization.k8s.io",
-				},
-				Resources: []string{
-					"roles",
-					"rolebindings",
-					"clusterroles",
-					"clusterrolebindings",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"apiextensions.k8s.io",
-				},
-				Resources: []string{
-					"customresourcedefinitions",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-			{
-				APIGroups: []string{
-					"cloud.atomix.io",
-					"k8s.atomix.io",
-				},
-				Resources: []string{
-					"*",
-				},
-				Verbs: []string{
-					"*",
-				},
-			},
-		},
-	}
-	_, err := n.Clientset().RbacV1().ClusterRoles().Create(role)
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
-		return err
-	}
-	return nil
-}
-
-// createClusterRoleBinding creates the ClusterRoleBinding required by the test manager
-func (n *Runner) createClusterRoleBinding() error {
-	roleBinding, err := n.Clientset().RbacV1().ClusterRoleBindings().Get(clusterRole, metav1.GetOptions{})
-	if err != nil {
-		if !k8serrors.IsNotFound(err) {
-			return err
-		}
-		roleBinding = &rbacv1.ClusterRoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: clusterRole,
-			},
-			Subjects: []rbacv1.Subject{
-				{
-					Kind:      "ServiceAccount",
-					Name:      n.Namespace(),
-					Namespace: n.Namespace(),
-				},
-			},
-			RoleRef: rbacv1.RoleRef{
-				Kind:     "ClusterRole",
-				Name:     clusterRole,
-				APIGroup: "rbac.authorization.k8s.io",
-			},
-		}
-		_, err := n.Clientset().RbacV1().ClusterRoleBindings().Create(roleBinding)
-		if err != nil && k8serrors.IsAlreadyExists(err) {
-			return n.createClusterRoleBinding()
-		}
-		return err
-	}
-
-	roleBinding.Subjects = append(roleBinding.Subjects, rbacv1.Subject{
-		Kind:      "ServiceAccount",
-		Name:      n.Namespace(),
-		Namespace: n.Namespace(),
-	})
-	_, err = n.Clientset().RbacV1().ClusterRoleBindings().Update(roleBinding)
-	if err != nil && k8serrors.IsConflict(err) {
-		return n.createClusterRoleBinding()
-	}
-	return err
-}
-
-// createServiceAccount creates a ServiceAccount used by the test manager
-func (n *Runner) createServiceAccount() error {
-	serviceAccount := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      n.Namespace(),
-			Namespace: n.Namespace(),
-		},
-	}
-	_, err := n.Clientset().CoreV1().ServiceAccounts(n.Namespace()).Create(serviceAccount)
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
-		return err
-	}
-	return nil
-}
-
 // teardownNamespace tears down the cluster namespace
 func (n *Runner) teardownNamespace() error {
 	step := logging.NewStep(n.Namespace(), "Delete namespace %s", n.Namespace())
 	step.Start()
 
-	w, err := n.Clientset().CoreV1().Namespaces().Watch(metav1.ListOptions{
+	w, err := n.clientset.CoreV1().Namespaces().Watch(metav1.ListOptions{
 		LabelSelector: "test=" + n.Namespace(),
 	})
 	if err != nil {
 		step.Fail(err)
 	}
 
-	err = n.Clientset().CoreV1().Namespaces().Delete(n.Namespace(), &metav1.DeleteOptions{})
+	err = n.clientset.CoreV1().Namespaces().Delete(n.Namespace(), &metav1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
@@ -358,6 +179,12 @@ func (n *Runner) teardownNamespace() error {
 			w.Stop()
 		}
 	}
+	n.stopWatchingPods()
+
+	if err := n.teardownRBAC(); err != nil {
+		step.Fail(err)
+		return err
+	}
 	step.Complete()
 	return nil
 }
@@ -366,6 +193,9 @@ func (n *Runner) teardownNamespace() error {
 func (n *Runner) startJob(job *Job) error {
 	step := logging.NewStep(job.ID, "Starting job")
 	step.Start()
+	if job.Timeout > 0 {
+		job.deadline = time.Now().Add(job.Timeout)
+	}
 	if err := n.createJob(job); err != nil {
 		step.Fail(err)
 		return err
@@ -465,7 +295,7 @@ func (n *Runner) createJob(job *Job) error {
 				Ports: servicePorts,
 			},
 		}
-		if _, err := n.Clientset().CoreV1().Services(n.Namespace()).Create(svc); err != nil {
+		if _, err := n.clientset.CoreV1().Services(n.Namespace()).Create(svc); err != nil {
 			return err
 		}
 	}
@@ -489,7 +319,7 @@ func (n *Runner) createJob(job *Job) error {
 			configFile: string(json),
 		},
 	}
-	if _, err := n.Clientset().CoreV1().ConfigMaps(n.Namespace()).Create(cm); err != nil {
+	if _, err := n.clientset.CoreV1().ConfigMaps(n.Namespace()).Create(cm); err != nil {
 		return err
 	}
 
@@ -514,6 +344,38 @@ func (n *Runner) createJob(job *Job) error {
 		},
 	}
 
+	var containers []corev1.Container
+	if job.ArtifactsDir != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "artifacts",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "artifacts",
+			MountPath: ArtifactsPath,
+		})
+
+		// The artifacts container keeps the pod running after the job exits so artifacts can be copied from it
+		containers = append(containers, corev1.Container{
+			Name:            artifactsContainer,
+			Image:           job.Image,
+			ImagePullPolicy: job.ImagePullPolicy,
+			Command: []string{
+				"/bin/sh",
+				"-c",
+				fmt.Sprintf("trap 'exit 0' TERM; while [ ! -f %s ]; do sleep 1; done", artifactsDoneFile),
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "artifacts",
+					MountPath: ArtifactsPath,
+				},
+			},
+		})
+	}
+
 	var containerPorts []corev1.ContainerPort
 	if n.server {
 		containerPorts = []corev1.ContainerPort{
@@ -575,9 +437,9 @@ func (n *Runner) createJob(job *Job) error {
 				Spec: corev1.PodSpec{
 					ServiceAccountName: n.Namespace(),
 					RestartPolicy:      corev1.RestartPolicyNever,
-					Containers: []corev1.Container{
+					Containers: append([]corev1.Container{
 						{
-							Name:            "job",
+							Name:            jobContainer,
 							Image:           job.Image,
 							ImagePullPolicy: job.ImagePullPolicy,
 							Args:            job.Args,
@@ -586,7 +448,7 @@ func (n *Runner) createJob(job *Job) error {
 							VolumeMounts:    volumeMounts,
 							ReadinessProbe:  readinessProbe,
 						},
-					},
+					}, containers...),
 					Volumes: volumes,
 				},
 			},
@@ -598,7 +460,16 @@ func (n *Runner) createJob(job *Job) error {
 		batchJob.Spec.ActiveDeadlineSeconds = &timeoutSeconds
 	}
 
-	_, err = n.Clientset().BatchV1().Jobs(n.Namespace()).Create(batchJob)
+	if job.PodTemplate != nil {
+		template, err := mergePodTemplate(batchJob.Spec.Template, *job.PodTemplate)
+		if err != nil {
+			step.Fail(err)
+			return err
+		}
+		batchJob.Spec.Template = template
+	}
+
+	_, err = n.clientset.BatchV1().Jobs(n.Namespace()).Create(batchJob)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -607,36 +478,45 @@ func (n *Runner) createJob(job *Job) error {
 	return nil
 }
 
+// mergePodTemplate merges the given overlay into the pod template using strategic merge semantics
+// Containers, volumes and environment variables in the overlay are merged with those of the same name
+// in the template, e.g. resources may be added to the job container by overlaying a container named "job".
+func mergePodTemplate(template, overlay corev1.PodTemplateSpec) (corev1.PodTemplateSpec, error) {
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		return template, err
+	}
+	overlayBytes, err := json.Marshal(overlay)
+	if err != nil {
+		return template, err
+	}
+	mergedBytes, err := strategicpatch.StrategicMergePatch(templateBytes, overlayBytes, corev1.PodTemplateSpec{})
+	if err != nil {
+		return template, err
+	}
+	merged := corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(mergedBytes, &merged); err != nil {
+		return template, err
+	}
+	return merged, nil
+}
+
 // awaitJobRunning blocks until the test job creates a pod in the RUNNING state
 func (n *Runner) awaitJobRunning(job *Job) error {
-	for {
-		pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-			return len(pod.Status.ContainerStatuses) > 0 &&
-				pod.Status.ContainerStatuses[0].State.Running != nil
-		})
-		if err != nil {
-			return err
-		} else if pod != nil {
-			return nil
-		}
-		time.Sleep(100 * time.Millisecond)
-	}
+	_, err := n.awaitPod(job, PhaseRunning, func(pod *corev1.Pod) bool {
+		status := getJobContainerStatus(pod)
+		return status != nil && status.State.Running != nil
+	})
+	return err
 }
 
 // awaitJobReady blocks until the test job creates a ready pod
 func (n *Runner) awaitJobReady(job *Job) error {
-	for {
-		pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-			return len(pod.Status.ContainerStatuses) > 0 &&
-				pod.Status.ContainerStatuses[0].Ready
-		})
-		if err != nil {
-			return err
-		} else if pod != nil {
-			return nil
-		}
-		time.Sleep(100 * time.Millisecond)
-	}
+	_, err := n.awaitPod(job, PhaseReady, func(pod *corev1.Pod) bool {
+		status := getJobContainerStatus(pod)
+		return status != nil && status.Ready
+	})
+	return err
 }
 
 // copyBinary copies the job binary to the pod
@@ -648,9 +528,7 @@ func (n *Runner) copyBinary(job *Job) error {
 	step := logging.NewStep(job.ID, "Copy binary %s", path.Base(job.Executable))
 	step.Start()
 
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return true
-	})
+	pod, err := n.getPod(job)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -659,7 +537,7 @@ func (n *Runner) copyBinary(job *Job) error {
 	err = files.Copy(n).
 		From(job.Executable).
 		To(job.Executable).
-		On(pod.Name).
+		On(pod.Name, jobContainer).
 		Do()
 	if err != nil {
 		step.Fail(err)
@@ -678,9 +556,7 @@ func (n *Runner) runBinary(job *Job) error {
 	step := logging.NewStep(job.ID, "Run binary %s", path.Base(job.Executable))
 	step.Start()
 
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return true
-	})
+	pod, err := n.getPod(job)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -688,7 +564,7 @@ func (n *Runner) runBinary(job *Job) error {
 	err = files.Echo(n).
 		String(path.Base(job.Executable)).
 		To("/tmp/bin-ready").
-		On(pod.Name).
+		On(pod.Name, jobContainer).
 		Do()
 	if err != nil {
 		step.Fail(err)
@@ -706,9 +582,7 @@ func (n *Runner) copyValueFiles(job *Job) error {
 	step := logging.NewStep(job.ID, "Copy value files")
 	step.Start()
 
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return true
-	})
+	pod, err := n.getPod(job)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -721,7 +595,7 @@ func (n *Runner) copyValueFiles(job *Job) error {
 			err := files.Copy(n).
 				From(valueFile).
 				To(valueFile).
-				On(pod.Name).
+				On(pod.Name, jobContainer).
 				Do()
 			if err != nil {
 				fileStep.Fail(err)
@@ -744,9 +618,7 @@ func (n *Runner) copyContext(job *Job) error {
 	step := logging.NewStep(job.ID, "Copy Helm context")
 	step.Start()
 
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return true
-	})
+	pod, err := n.getPod(job)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -755,7 +627,7 @@ func (n *Runner) copyContext(job *Job) error {
 	err = files.Copy(n).
 		From(job.Context).
 		To(job.Context).
-		On(pod.Name).
+		On(pod.Name, jobContainer).
 		Do()
 	if err != nil {
 		step.Fail(err)
@@ -770,9 +642,7 @@ func (n *Runner) runJob(job *Job) error {
 	step := logging.NewStep(job.ID, "Run job")
 	step.Start()
 
-	pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-		return true
-	})
+	pod, err := n.getPod(job)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -780,7 +650,7 @@ func (n *Runner) runJob(job *Job) error {
 	err = files.Echo(n).
 		String(path.Base(job.Context)).
 		To(readyFile).
-		On(pod.Name).
+		On(pod.Name, jobContainer).
 		Do()
 	if err != nil {
 		step.Fail(err)
@@ -791,36 +661,157 @@ func (n *Runner) runJob(job *Job) error {
 
 // getStatus gets the status message and exit code of the given pod
 func (n *Runner) getStatus(job *Job) (string, int, error) {
-	for {
-		pod, err := n.getPod(job, func(pod corev1.Pod) bool {
-			return len(pod.Status.ContainerStatuses) > 0 &&
-				pod.Status.ContainerStatuses[0].State.Terminated != nil
-		})
-		if err != nil {
-			return "", 0, err
-		} else if pod != nil {
-			state := pod.Status.ContainerStatuses[0].State
-			if state.Terminated != nil {
-				return state.Terminated.Message, int(state.Terminated.ExitCode), nil
-			}
+	pod, err := n.awaitPod(job, PhaseComplete, func(pod *corev1.Pod) bool {
+		status := getJobContainerStatus(pod)
+		return status != nil && status.State.Terminated != nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	state := getJobContainerStatus(pod).State
+	return state.Terminated.Message, int(state.Terminated.ExitCode), nil
+}
+
+// getJobContainerStatus returns the status of the job container in the given pod
+func getJobContainerStatus(pod *corev1.Pod) *corev1.ContainerStatus {
+	for i, status := range pod.Status.ContainerStatuses {
+		if status.Name == jobContainer {
+			return &pod.Status.ContainerStatuses[i]
 		}
-		time.Sleep(100 * time.Millisecond)
 	}
+	return nil
 }
 
 // getPod finds the Pod for the given test
-func (n *Runner) getPod(job *Job, predicate func(pod corev1.Pod) bool) (*corev1.Pod, error) {
-	pods, err := n.Clientset().CoreV1().Pods(n.Namespace()).List(metav1.ListOptions{
-		LabelSelector: "job=" + job.ID,
+func (n *Runner) getPod(job *Job) (*corev1.Pod, error) {
+	pods, err := n.watchPods(job, PhaseRunning, n.cancelCh)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := pods.get(job, func(pod *corev1.Pod) bool {
+		return true
 	})
 	if err != nil {
 		return nil, err
-	} else if len(pods.Items) > 0 {
-		for _, pod := range pods.Items {
-			if predicate(pod) {
-				return &pod, nil
+	} else if pod == nil {
+		return nil, fmt.Errorf("no pod found for job %s", job.ID)
+	}
+	return pod, nil
+}
+
+// awaitPod blocks until a Pod for the given job matches the predicate
+// If the job's timeout expires before the pod reaches the given phase, a TimeoutError is returned. If the
+// pod fails in a way from which it cannot recover before reaching the phase, a PodError is returned.
+func (n *Runner) awaitPod(job *Job, phase Phase, predicate func(pod *corev1.Pod) bool) (*corev1.Pod, error) {
+	pods, err := n.watchPods(job, phase, n.cancelCh)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := pods.await(job, func(pod *corev1.Pod) bool {
+		if predicate(pod) {
+			return true
+		}
+		_, _, failed := getPodFailure(pod)
+		return failed
+	}, job.deadline, n.cancelCh)
+	if err != nil {
+		return nil, err
+	} else if pod == nil {
+		return nil, &TimeoutError{
+			Job:     job.ID,
+			Phase:   phase,
+			Timeout: job.Timeout,
+		}
+	} else if !predicate(pod) {
+		return nil, n.newPodError(job, phase, pod)
+	}
+	return pod, nil
+}
+
+// maxPodErrorEvents is the maximum number of events to include in a PodError
+const maxPodErrorEvents = 10
+
+// newPodError returns a PodError describing the failure of the given pod
+func (n *Runner) newPodError(job *Job, phase Phase, pod *corev1.Pod) error {
+	reason, message, _ := getPodFailure(pod)
+	podErr := &PodError{
+		Job:        job.ID,
+		Pod:        pod.Name,
+		Phase:      phase,
+		Reason:     reason,
+		Message:    message,
+		Containers: pod.Status.ContainerStatuses,
+	}
+
+	events, err := n.clientset.CoreV1().Events(n.Namespace()).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", pod.Name).String(),
+	})
+	if err == nil {
+		items := events.Items
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+		})
+		if len(items) > maxPodErrorEvents {
+			items = items[len(items)-maxPodErrorEvents:]
+		}
+		podErr.Events = items
+	}
+	return podErr
+}
+
+// getPodFailure returns the reason and message for a pod that cannot make progress
+func getPodFailure(pod *corev1.Pod) (string, string, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return condition.Reason, condition.Message, true
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CrashLoopBackOff":
+				return waiting.Reason, waiting.Message, true
+			}
+		}
+		for _, state := range []corev1.ContainerState{status.State, status.LastTerminationState} {
+			if terminated := state.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+				return terminated.Reason, terminated.Message, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// watchPods returns the watcher for job pods in the namespace, starting it if necessary
+// If the watcher cannot be started before the job's timeout, a TimeoutError for the given phase is returned. If the
+// cancel channel is closed before the watcher is started, ErrCanceled is returned.
+func (n *Runner) watchPods(job *Job, phase Phase, cancelCh <-chan struct{}) (*podWatcher, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pods == nil {
+		pods, err := newPodWatcher(n.clientset, n.Namespace(), job.deadline, cancelCh)
+		if err == errSyncTimeout {
+			return nil, &TimeoutError{
+				Job:     job.ID,
+				Phase:   phase,
+				Timeout: job.Timeout,
 			}
+		} else if err != nil {
+			return nil, err
 		}
+		n.pods = pods
+	}
+	return n.pods, nil
+}
+
+// stopWatchingPods stops the job pod watcher if it's running
+func (n *Runner) stopWatchingPods() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pods != nil {
+		n.pods.stop()
+		n.pods = nil
 	}
-	return nil, nil
 }
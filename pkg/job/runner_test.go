@@ -0,0 +1,129 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"testing"
+)
+
+func TestGetPodFailure(t *testing.T) {
+	pod := &corev1.Pod{}
+	_, _, failed := getPodFailure(pod)
+	assert.False(t, failed)
+
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "job",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason: "ContainerCreating",
+				},
+			},
+		},
+	}
+	_, _, failed = getPodFailure(pod)
+	assert.False(t, failed)
+
+	pod.Status.ContainerStatuses[0].State.Waiting.Reason = "ImagePullBackOff"
+	pod.Status.ContainerStatuses[0].State.Waiting.Message = "Back-off pulling image"
+	reason, message, failed := getPodFailure(pod)
+	assert.True(t, failed)
+	assert.Equal(t, "ImagePullBackOff", reason)
+	assert.Equal(t, "Back-off pulling image", message)
+
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{
+			Reason:   "OOMKilled",
+			ExitCode: 137,
+		},
+	}
+	reason, _, failed = getPodFailure(pod)
+	assert.True(t, failed)
+	assert.Equal(t, "OOMKilled", reason)
+
+	pod = &corev1.Pod{
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/3 nodes are available",
+				},
+			},
+		},
+	}
+	reason, message, failed = getPodFailure(pod)
+	assert.True(t, failed)
+	assert.Equal(t, corev1.PodReasonUnschedulable, reason)
+	assert.Equal(t, "0/3 nodes are available", message)
+}
+
+func TestMergePodTemplate(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "test",
+			Containers: []corev1.Container{
+				{
+					Name:  "job",
+					Image: "onosproject/helmit-runner:latest",
+					Env: []corev1.EnvVar{
+						{
+							Name:  "JOB_TYPE",
+							Value: "test",
+						},
+					},
+				},
+			},
+		},
+	}
+	overlay := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{
+				"pool": "ci",
+			},
+			ImagePullSecrets: []corev1.LocalObjectReference{
+				{
+					Name: "registry",
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name: "job",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("500m"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	merged, err := mergePodTemplate(template, overlay)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", merged.Spec.ServiceAccountName)
+	assert.Equal(t, "ci", merged.Spec.NodeSelector["pool"])
+	assert.Len(t, merged.Spec.ImagePullSecrets, 1)
+	assert.Len(t, merged.Spec.Containers, 1)
+	container := merged.Spec.Containers[0]
+	assert.Equal(t, "onosproject/helmit-runner:latest", container.Image)
+	assert.Len(t, container.Env, 1)
+	cpu := container.Resources.Requests[corev1.ResourceCPU]
+	assert.Equal(t, "500m", cpu.String())
+}
@@ -0,0 +1,295 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/kubernetes"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunInfo describes a run of a test, benchmark or simulation coordinator
+type RunInfo struct {
+	// ID is the unique run ID
+	ID string
+	// Type is the type of the run, e.g. test, benchmark or simulation
+	Type string
+	// Status is the status of the run's coordinator
+	Status string
+	// Active indicates whether the run's coordinator is still running
+	Active bool
+	// Created is the time at which the run was started
+	Created time.Time
+	// Namespaces is the list of namespaces created by the run
+	Namespaces []string
+}
+
+// ListRuns lists the runs of coordinators in the cluster, ordered by start time
+// If all is false, only runs with an active coordinator are returned.
+func ListRuns(all bool) ([]RunInfo, error) {
+	client, err := kubernetes.NewForNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return listRuns(client.Clientset(), all)
+}
+
+// listRuns lists the runs of coordinators using the given client
+func listRuns(client k8s.Interface, all bool) ([]RunInfo, error) {
+	jobs, err := client.BatchV1().Jobs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{
+		LabelSelector: "job",
+	})
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: "test",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobPods := make(map[string]*corev1.Pod)
+	for i, pod := range pods.Items {
+		jobPods[pod.Labels["job"]] = &pods.Items[i]
+	}
+
+	runs := make([]RunInfo, 0, len(jobs.Items))
+	for _, job := range jobs.Items {
+		id, ok := job.Annotations["job"]
+		if !ok {
+			continue
+		}
+
+		run := RunInfo{
+			ID:      id,
+			Type:    job.Annotations["type"],
+			Created: job.CreationTimestamp.Time,
+		}
+		if pod, ok := jobPods[id]; ok {
+			run.Status, run.Active = getRunStatus(pod)
+		} else if job.Status.Active > 0 {
+			run.Status, run.Active = "Pending", true
+		} else if job.Status.Failed > 0 {
+			run.Status = "Failed"
+		} else {
+			run.Status = "Complete"
+		}
+		if !run.Active && !all {
+			continue
+		}
+
+		// Namespaces created by the coordinator are prefixed with the run ID
+		for _, ns := range namespaces.Items {
+			if strings.HasPrefix(ns.Name, id+"-") {
+				run.Namespaces = append(run.Namespaces, ns.Name)
+			}
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Created.Before(runs[j].Created)
+	})
+	return runs, nil
+}
+
+// getRunStatus returns the status of the given coordinator pod and whether it's active
+func getRunStatus(pod *corev1.Pod) (string, bool) {
+	if pod.DeletionTimestamp != nil {
+		return "Canceling", true
+	}
+	if reason, _, failed := getPodFailure(pod); failed {
+		return reason, true
+	}
+	status := getJobContainerStatus(pod)
+	switch {
+	case status == nil:
+		return string(pod.Status.Phase), pod.Status.Phase == corev1.PodPending || pod.Status.Phase == corev1.PodRunning
+	case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+		return "Succeeded", false
+	case status.State.Terminated != nil && status.State.Terminated.ExitCode == CanceledExitCode:
+		return "Canceled", false
+	case status.State.Terminated != nil:
+		return fmt.Sprintf("Failed (exit code %d)", status.State.Terminated.ExitCode), false
+	case status.State.Running != nil:
+		return "Running", true
+	}
+	return "Pending", true
+}
+
+// StreamLogs writes the logs of the run with the given ID to the given writer
+// If workers is true, the logs of the job pods in each of the run's namespaces are written instead of the
+// logs of the run's coordinator, with each line prefixed by the namespace and name of the pod.
+func StreamLogs(out io.Writer, id string, follow bool, workers bool) error {
+	client, err := kubernetes.NewForNamespace(namespace)
+	if err != nil {
+		return err
+	}
+
+	if !workers {
+		pods, err := client.Clientset().CoreV1().Pods(namespace).List(metav1.ListOptions{
+			LabelSelector: "job=" + id,
+		})
+		if err != nil {
+			return err
+		} else if len(pods.Items) == 0 {
+			return fmt.Errorf("unknown run %s", id)
+		}
+		return streamPodLogs(client, namespace, pods.Items[0].Name, follow, "", out)
+	}
+
+	runs, err := ListRuns(true)
+	if err != nil {
+		return err
+	}
+
+	var run *RunInfo
+	for i := range runs {
+		if runs[i].ID == id {
+			run = &runs[i]
+		}
+	}
+	if run == nil {
+		return fmt.Errorf("unknown run %s", id)
+	}
+
+	wg := &sync.WaitGroup{}
+	errCh := make(chan error, len(run.Namespaces))
+	writer := &syncWriter{writer: out}
+	for _, ns := range run.Namespaces {
+		pods, err := client.Clientset().CoreV1().Pods(ns).List(metav1.ListOptions{
+			LabelSelector: "job",
+		})
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods.Items {
+			wg.Add(1)
+			go func(ns, pod string) {
+				defer wg.Done()
+				if err := streamPodLogs(client, ns, pod, follow, fmt.Sprintf("%s/%s ", ns, pod), writer); err != nil {
+					errCh <- err
+				}
+			}(ns, pod.Name)
+		}
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return err
+	}
+	return nil
+}
+
+// streamPodLogs writes the logs of the given pod's job container to the given writer
+func streamPodLogs(client kubernetes.Client, namespace, pod string, follow bool, prefix string, out io.Writer) error {
+	req := client.Clientset().CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container: jobContainer,
+		Follow:    follow,
+	})
+	reader, err := req.Stream()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintln(out, prefix+scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// syncWriter is a writer that serializes writes from multiple goroutines
+type syncWriter struct {
+	writer io.Writer
+	mu     sync.Mutex
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(p)
+}
+
+// Attach attaches to the run with the given ID, streaming its logs until it exits
+// If the run collects artifacts, they're copied to the given artifacts directory once the run is complete, or to
+// the default artifacts directory if none is provided. The exit code of the run's coordinator is returned.
+func Attach(id string, artifactsDir string) (int, error) {
+	if artifactsDir == "" {
+		artifactsDir = DefaultArtifactsDir()
+	}
+	coordinator := NewCoordinator(nil)
+	job := &Job{
+		Config: &Config{
+			ID:           id,
+			ArtifactsDir: artifactsDir,
+		},
+	}
+
+	pod, err := coordinator.getPod(job)
+	if err != nil {
+		return 0, fmt.Errorf("unknown run %s", id)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		coordinator.streamLogs(job)
+		close(doneCh)
+	}()
+	status, err := coordinator.WaitForExit(job)
+	if err != nil {
+		if hasArtifacts(pod) {
+			_ = coordinator.ReleaseArtifacts(job)
+		}
+		return 0, err
+	}
+	<-doneCh
+
+	if hasArtifacts(pod) {
+		dir := filepath.Join(artifactsDir, id)
+		if err := coordinator.CollectArtifacts(job, dir); err == nil {
+			PrintDiagnostics(os.Stdout, dir)
+		}
+	}
+	return status, nil
+}
+
+// hasArtifacts returns whether the given pod collects artifacts
+func hasArtifacts(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == artifactsContainer {
+			return true
+		}
+	}
+	return false
+}
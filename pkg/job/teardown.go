@@ -0,0 +1,72 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"fmt"
+	"github.com/onosproject/helmit/pkg/util/logging"
+	"io"
+	"sync"
+)
+
+// retainedNamespaces is the list of namespaces that were not torn down by this process
+var retainedNamespaces []string
+var retainedMu sync.Mutex
+
+// ShouldTearDown returns whether the namespace for the job should be torn down
+func (c *Config) ShouldTearDown(failed bool) bool {
+	return !c.NoTeardown && !(failed && c.KeepOnFailure)
+}
+
+// TearDown tears down the namespace unless the configuration requires it to be retained
+// Retained namespaces are printed along with the commands for inspecting them by PrintRetainedNamespaces.
+func (n *Runner) TearDown(config *Config, failed bool) error {
+	if config.ShouldTearDown(failed) {
+		return n.DeleteNamespace()
+	}
+
+	step := logging.NewStep(n.Namespace(), "Retain namespace %s", n.Namespace())
+	step.Start()
+	n.stopWatchingPods()
+	retainedMu.Lock()
+	retainedNamespaces = append(retainedNamespaces, n.Namespace())
+	retainedMu.Unlock()
+	step.Complete()
+	return nil
+}
+
+// PrintRetainedNamespaces prints the namespaces that were not torn down and the commands for inspecting them
+func PrintRetainedNamespaces(out io.Writer) {
+	retainedMu.Lock()
+	defer retainedMu.Unlock()
+	if len(retainedNamespaces) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "The following namespaces were not torn down:")
+	for _, namespace := range retainedNamespaces {
+		fmt.Fprintf(out, "  %s\n", namespace)
+	}
+	fmt.Fprintln(out, "To inspect them, run:")
+	for _, namespace := range retainedNamespaces {
+		fmt.Fprintf(out, "  kubectl get all -n %s\n", namespace)
+		fmt.Fprintf(out, "  kubectl get events -n %s --sort-by=.lastTimestamp\n", namespace)
+		fmt.Fprintf(out, "  kubectl logs -n %s -l job --all-containers\n", namespace)
+	}
+	fmt.Fprintln(out, "To delete them, run:")
+	for _, namespace := range retainedNamespaces {
+		fmt.Fprintf(out, "  kubectl delete namespace %s\n", namespace)
+	}
+}
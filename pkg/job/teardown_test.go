@@ -0,0 +1,34 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestShouldTearDown(t *testing.T) {
+	config := &Config{}
+	assert.True(t, config.ShouldTearDown(false))
+	assert.True(t, config.ShouldTearDown(true))
+
+	config.KeepOnFailure = true
+	assert.True(t, config.ShouldTearDown(false))
+	assert.False(t, config.ShouldTearDown(true))
+
+	config.NoTeardown = true
+	assert.False(t, config.ShouldTearDown(false))
+	assert.False(t, config.ShouldTearDown(true))
+}
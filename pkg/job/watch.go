@@ -0,0 +1,182 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sync"
+	"time"
+)
+
+// errSyncTimeout is returned when the pod cache is not synced before the deadline
+var errSyncTimeout = errors.New("timed out syncing job pods")
+
+// newPodWatcher starts a watcher for job pods in the given namespace
+// The watcher waits for the pod cache to sync until the deadline, if set, or until the cancel channel is closed. If
+// the cache cannot be synced, the watcher is stopped and errSyncTimeout or ErrCanceled is returned.
+func newPodWatcher(client k8s.Interface, namespace string, deadline time.Time, cancelCh <-chan struct{}) (*podWatcher, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = "job"
+		}))
+	pods := factory.Core().V1().Pods()
+	watcher := &podWatcher{
+		informer: pods.Informer(),
+		lister:   pods.Lister().Pods(namespace),
+		stopCh:   make(chan struct{}),
+		changeCh: make(chan struct{}),
+	}
+	watcher.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			watcher.notify()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			watcher.notify()
+		},
+		DeleteFunc: func(obj interface{}) {
+			watcher.notify()
+		},
+	})
+	go watcher.informer.Run(watcher.stopCh)
+
+	var timeoutCh <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	syncCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	go func() {
+		select {
+		case <-timeoutCh:
+		case <-cancelCh:
+		case <-doneCh:
+		}
+		close(syncCh)
+	}()
+	if !cache.WaitForCacheSync(syncCh, watcher.informer.HasSynced) {
+		watcher.stop()
+		select {
+		case <-cancelCh:
+			return nil, ErrCanceled
+		default:
+			return nil, errSyncTimeout
+		}
+	}
+	return watcher, nil
+}
+
+// podWatcher caches the job pods in a namespace and notifies waiters of changes
+type podWatcher struct {
+	informer cache.SharedIndexInformer
+	lister   corelisters.PodNamespaceLister
+	stopCh   chan struct{}
+	changeCh chan struct{}
+	mu       sync.RWMutex
+}
+
+// notify wakes up all goroutines waiting for a pod change
+func (w *podWatcher) notify() {
+	w.mu.Lock()
+	close(w.changeCh)
+	w.changeCh = make(chan struct{})
+	w.mu.Unlock()
+}
+
+// changes returns a channel that's closed on the next pod change
+func (w *podWatcher) changes() <-chan struct{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.changeCh
+}
+
+// get returns the first cached pod for the given job matching the predicate
+func (w *podWatcher) get(job *Job, predicate func(pod *corev1.Pod) bool) (*corev1.Pod, error) {
+	pods, err := w.lister.List(labels.SelectorFromSet(labels.Set{"job": job.ID}))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		if predicate(pod) {
+			return pod.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
+
+// await blocks until a pod for the given job matches the predicate
+// If the deadline is reached before a matching pod is found, a nil pod is returned. If the cancel channel
+// is closed before a matching pod is found, ErrCanceled is returned.
+func (w *podWatcher) await(job *Job, predicate func(pod *corev1.Pod) bool, deadline time.Time, cancelCh <-chan struct{}) (*corev1.Pod, error) {
+	var timeoutCh <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	for {
+		// Get the change channel before reading the cache to ensure no updates are missed
+		changeCh := w.changes()
+		pod, err := w.get(job, predicate)
+		if err != nil || pod != nil {
+			return pod, err
+		}
+
+		select {
+		case <-changeCh:
+		case <-timeoutCh:
+			return nil, nil
+		case <-cancelCh:
+			return nil, ErrCanceled
+		case <-w.stopCh:
+			return nil, errors.New("job watch stopped")
+		}
+	}
+}
+
+// awaitRemoved blocks until no pods remain for the given job
+func (w *podWatcher) awaitRemoved(job *Job) error {
+	for {
+		changeCh := w.changes()
+		pod, err := w.get(job, func(pod *corev1.Pod) bool {
+			return true
+		})
+		if err != nil || pod == nil {
+			return err
+		}
+
+		select {
+		case <-changeCh:
+		case <-w.stopCh:
+			return errors.New("job watch stopped")
+		}
+	}
+}
+
+// stop stops watching pods
+func (w *podWatcher) stop() {
+	close(w.stopCh)
+}
@@ -0,0 +1,168 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"testing"
+	"time"
+)
+
+// newJobPod returns a pod for the given job in the given phase
+func newJobPod(job *Job, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.ID + "-pod",
+			Namespace: "test",
+			Labels:    map[string]string{"job": job.ID},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+// isRunning returns whether the given pod is running
+func isRunning(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodRunning
+}
+
+// newTestWatcher starts a pod watcher on the given clientset and waits for its watch to be established
+// The fake clientset does not replay changes made between listing and watching pods.
+func newTestWatcher(t *testing.T, clientset *fake.Clientset) *podWatcher {
+	watcher, err := newPodWatcher(clientset, "test", time.Time{}, nil)
+	assert.NoError(t, err)
+	for {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				return watcher
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPodWatcherAwait(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job"}}
+	clientset := fake.NewSimpleClientset(newJobPod(job, corev1.PodPending))
+	watcher := newTestWatcher(t, clientset)
+	defer watcher.stop()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := clientset.CoreV1().Pods("test").UpdateStatus(newJobPod(job, corev1.PodRunning))
+		assert.NoError(t, err)
+	}()
+	pod, err := watcher.await(job, isRunning, time.Now().Add(10*time.Second), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.PodRunning, pod.Status.Phase)
+
+	// Pods for other jobs are ignored
+	pod, err = watcher.await(&Job{Config: &Config{ID: "other"}}, isRunning, time.Now().Add(50*time.Millisecond), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, pod)
+}
+
+func TestPodWatcherAwaitCanceled(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job"}}
+	clientset := fake.NewSimpleClientset(newJobPod(job, corev1.PodPending))
+	watcher := newTestWatcher(t, clientset)
+	defer watcher.stop()
+
+	cancelCh := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(cancelCh)
+	}()
+	pod, err := watcher.await(job, isRunning, time.Time{}, cancelCh)
+	assert.Equal(t, ErrCanceled, err)
+	assert.Nil(t, pod)
+}
+
+func TestPodWatcherAwaitRemoved(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job"}}
+	clientset := fake.NewSimpleClientset(newJobPod(job, corev1.PodRunning))
+	watcher := newTestWatcher(t, clientset)
+	defer watcher.stop()
+
+	removedCh := make(chan error)
+	go func() {
+		removedCh <- watcher.awaitRemoved(job)
+	}()
+	select {
+	case <-removedCh:
+		t.Fatal("pod not yet removed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, clientset.CoreV1().Pods("test").Delete(job.ID+"-pod", &metav1.DeleteOptions{}))
+	select {
+	case err := <-removedCh:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("pod removal not observed")
+	}
+}
+
+func TestAwaitPodTimeout(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job", Timeout: 100 * time.Millisecond}}
+	job.deadline = time.Now().Add(job.Timeout)
+	runner := newTestRunner(fake.NewSimpleClientset(newJobPod(job, corev1.PodPending)), "test", nil)
+	defer runner.stopWatchingPods()
+
+	_, err := runner.awaitPod(job, PhaseRunning, isRunning)
+	assert.True(t, IsTimeout(err))
+	assert.Equal(t, PhaseRunning, err.(*TimeoutError).Phase)
+}
+
+// newForbiddenClientset returns a clientset that's forbidden from listing pods
+func newForbiddenClientset() *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil)
+	})
+	return clientset
+}
+
+func TestWatchPodsSyncTimeout(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job", Timeout: 100 * time.Millisecond}}
+	job.deadline = time.Now().Add(job.Timeout)
+	runner := newTestRunner(newForbiddenClientset(), "test", nil)
+
+	// Jobs whose pods cannot be watched time out rather than blocking forever
+	_, err := runner.awaitPod(job, PhaseReady, isRunning)
+	assert.True(t, IsTimeout(err))
+	assert.Equal(t, PhaseReady, err.(*TimeoutError).Phase)
+	assert.Nil(t, runner.pods)
+}
+
+func TestWatchPodsSyncCanceled(t *testing.T) {
+	job := &Job{Config: &Config{ID: "job"}}
+	runner := newTestRunner(newForbiddenClientset(), "test", nil)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		runner.Cancel()
+	}()
+	_, err := runner.awaitPod(job, PhaseRunning, isRunning)
+	assert.Equal(t, ErrCanceled, err)
+	assert.Nil(t, runner.pods)
+}
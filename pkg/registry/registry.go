@@ -15,12 +15,15 @@
 package registry
 
 var tests = make(map[string]interface{})
+var testTags = make(map[string][]string)
 var benchmarks = make(map[string]interface{})
 var simulations = make(map[string]interface{})
 
 // RegisterTestSuite registers a test suite
-func RegisterTestSuite(name string, suite interface{}) {
+// Tags, e.g. "slow" or "upgrade", can be used to select or skip suites when running tests.
+func RegisterTestSuite(name string, suite interface{}, tags ...string) {
 	tests[name] = suite
+	testTags[name] = tags
 }
 
 // GetTestSuites returns a list of registered tests
@@ -37,6 +40,11 @@ func GetTestSuite(name string) interface{} {
 	return tests[name]
 }
 
+// GetTestSuiteTags gets the tags of a registered test suite by name
+func GetTestSuiteTags(name string) []string {
+	return testTags[name]
+}
+
 // RegisterBenchmarkSuite registers a benchmark suite
 func RegisterBenchmarkSuite(name string, suite interface{}) {
 	benchmarks[name] = suite
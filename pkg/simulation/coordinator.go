@@ -24,6 +24,7 @@ import (
 	"github.com/onosproject/helmit/pkg/util/logging"
 	"google.golang.org/grpc"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -42,6 +43,11 @@ type Coordinator struct {
 
 // Run runs the simulations
 func (c *Coordinator) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := job.OnCancel(cancel)
+	defer stop()
+
 	var suites []string
 	if c.config.Simulation == "" {
 		suites = registry.GetSimulationSuites()
@@ -61,8 +67,15 @@ func (c *Coordinator) Run() error {
 				Context:         c.config.Config.Context,
 				Values:          c.config.Config.Values,
 				ValueFiles:      c.config.Config.ValueFiles,
+				StrictValues:    c.config.Config.StrictValues,
+				HelmDriver:      c.config.Config.HelmDriver,
 				Env:             c.config.Config.Env,
 				Timeout:         c.config.Config.Timeout,
+				PodTemplate:     c.config.Config.PodTemplate,
+				NoTeardown:      c.config.Config.NoTeardown,
+				KeepOnFailure:   c.config.Config.KeepOnFailure,
+				ArtifactsDir:    c.config.Config.ArtifactsDir,
+				RBAC:            c.config.Config.RBAC.WithSuite(registry.GetSimulationSuite(suite)),
 			},
 			Simulation: suite,
 			Simulators: c.config.Simulators,
@@ -72,16 +85,21 @@ func (c *Coordinator) Run() error {
 			Args:       c.config.Args,
 		}
 		worker := &WorkerTask{
-			runner: job.NewNamespace(jobID),
+			runner: job.NewNamespace(jobID, config.RBAC),
 			config: config,
 		}
 		workers[i] = worker
 	}
-	return runWorkers(workers)
+	err := runWorkers(ctx, workers)
+	job.PrintRetainedNamespaces(os.Stdout)
+	if ctx.Err() != nil {
+		return job.ErrCanceled
+	}
+	return err
 }
 
 // runWorkers runs the given test jobs
-func runWorkers(tasks []*WorkerTask) error {
+func runWorkers(ctx context.Context, tasks []*WorkerTask) error {
 	// Start jobs in separate goroutines
 	wg := &sync.WaitGroup{}
 	errChan := make(chan error, len(tasks))
@@ -89,7 +107,7 @@ func runWorkers(tasks []*WorkerTask) error {
 	for _, task := range tasks {
 		wg.Add(1)
 		go func(task *WorkerTask) {
-			status, err := task.Run()
+			status, err := task.Run(ctx)
 			if err != nil {
 				errChan <- err
 			} else {
@@ -129,38 +147,59 @@ func newJobID(testID, suite string) string {
 type WorkerTask struct {
 	runner  *job.Runner
 	config  *Config
+	jobs    []*job.Job
+	jobsMu  sync.Mutex
 	workers []SimulatorServiceClient
 }
 
 // Run runs the worker job
-func (t *WorkerTask) Run() (int, error) {
+// If the context is canceled, the simulators are stopped and torn down and the namespace is deleted unless
+// teardown is disabled.
+func (t *WorkerTask) Run(ctx context.Context) (int, error) {
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.runner.Cancel()
+		case <-doneCh:
+		}
+	}()
+
 	// Start the job
-	err := t.run()
-	if err != nil {
-		_ = t.tearDown()
-		return 0, err
+	err := t.run(ctx)
+	close(doneCh)
+	failed := ctx.Err() == nil && err != nil
+	if failed && t.config.ArtifactsDir != "" {
+		_ = t.runner.CaptureDiagnostics(filepath.Join(t.config.ArtifactsDir, t.config.Simulation, job.DiagnosticsDir))
+	}
+	if ctx.Err() == nil {
+		t.collectArtifacts()
+	} else {
+		t.releaseArtifacts()
 	}
 
 	// Tear down the cluster if necessary
-	_ = t.tearDown()
-	return 0, nil
+	_ = t.tearDown(failed)
+	return 0, err
 }
 
 // start starts the test job
-func (t *WorkerTask) run() error {
+func (t *WorkerTask) run(ctx context.Context) error {
 	if err := t.runner.CreateNamespace(); err != nil {
 		return err
 	}
 	if err := t.createWorkers(); err != nil {
 		return err
 	}
-	if err := t.setupSimulation(); err != nil {
+	if err := t.setupSimulation(ctx); err != nil {
 		return err
 	}
-	if err := t.setupSimulators(); err != nil {
+	defer t.tearDownSimulation()
+	if err := t.setupSimulators(ctx); err != nil {
 		return err
 	}
-	if err := t.runSimulation(); err != nil {
+	defer t.tearDownSimulators()
+	if err := t.runSimulation(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -200,8 +239,15 @@ func (t *WorkerTask) createWorker(worker int) error {
 			Context:         t.config.Config.Context,
 			Values:          t.config.Config.Values,
 			ValueFiles:      t.config.Config.ValueFiles,
+			StrictValues:    t.config.Config.StrictValues,
+			HelmDriver:      t.config.Config.HelmDriver,
 			Env:             env,
 			Timeout:         t.config.Config.Timeout,
+			PodTemplate:     t.config.Config.PodTemplate,
+			NoTeardown:      t.config.Config.NoTeardown,
+			KeepOnFailure:   t.config.Config.KeepOnFailure,
+			ArtifactsDir:    t.config.Config.ArtifactsDir,
+			RBAC:            t.config.Config.RBAC,
 		},
 		JobConfig: &Config{
 			Config: &job.Config{
@@ -212,8 +258,15 @@ func (t *WorkerTask) createWorker(worker int) error {
 				Context:         t.config.Config.Context,
 				Values:          t.config.Config.Values,
 				ValueFiles:      t.config.Config.ValueFiles,
+				StrictValues:    t.config.Config.StrictValues,
+				HelmDriver:      t.config.Config.HelmDriver,
 				Env:             env,
 				Timeout:         t.config.Config.Timeout,
+				PodTemplate:     t.config.Config.PodTemplate,
+				NoTeardown:      t.config.Config.NoTeardown,
+				KeepOnFailure:   t.config.Config.KeepOnFailure,
+				ArtifactsDir:    t.config.Config.ArtifactsDir,
+				RBAC:            t.config.Config.RBAC,
 			},
 			Simulation: t.config.Simulation,
 			Simulators: t.config.Simulators,
@@ -224,6 +277,9 @@ func (t *WorkerTask) createWorker(worker int) error {
 		},
 		Type: simulationJobType,
 	}
+	t.jobsMu.Lock()
+	t.jobs = append(t.jobs, job)
+	t.jobsMu.Unlock()
 	return t.runner.StartJob(job)
 }
 
@@ -246,14 +302,14 @@ func (t *WorkerTask) getSimulators() ([]SimulatorServiceClient, error) {
 }
 
 // setupSimulation sets up the simulation
-func (t *WorkerTask) setupSimulation() error {
+func (t *WorkerTask) setupSimulation(ctx context.Context) error {
 	workers, err := t.getSimulators()
 	if err != nil {
 		return err
 	}
 
 	worker := workers[0]
-	_, err = worker.SetupSimulation(context.Background(), &SimulationLifecycleRequest{
+	_, err = worker.SetupSimulation(ctx, &SimulationLifecycleRequest{
 		Simulation: t.config.Simulation,
 		Args:       t.config.Args,
 	})
@@ -261,7 +317,7 @@ func (t *WorkerTask) setupSimulation() error {
 }
 
 // setupSimulators sets up the simulators
-func (t *WorkerTask) setupSimulators() error {
+func (t *WorkerTask) setupSimulators(ctx context.Context) error {
 	simulators, err := t.getSimulators()
 	if err != nil {
 		return err
@@ -272,7 +328,7 @@ func (t *WorkerTask) setupSimulators() error {
 	for i, simulator := range simulators {
 		wg.Add(1)
 		go func(simulator int, client SimulatorServiceClient) {
-			if err := t.setupSimulator(simulator, client); err != nil {
+			if err := t.setupSimulator(ctx, simulator, client); err != nil {
 				errCh <- err
 			}
 			wg.Done()
@@ -288,14 +344,14 @@ func (t *WorkerTask) setupSimulators() error {
 }
 
 // setupSimulator sets up the given simulator
-func (t *WorkerTask) setupSimulator(simulator int, client SimulatorServiceClient) error {
+func (t *WorkerTask) setupSimulator(ctx context.Context, simulator int, client SimulatorServiceClient) error {
 	step := logging.NewStep(t.config.ID, "Setup simulator %s/%d", t.config.Simulation, simulator)
 	step.Start()
 	request := &SimulationLifecycleRequest{
 		Simulation: t.config.Simulation,
 		Args:       t.config.Args,
 	}
-	_, err := client.SetupSimulator(context.Background(), request)
+	_, err := client.SetupSimulator(ctx, request)
 	if err != nil {
 		step.Fail(err)
 		return err
@@ -305,7 +361,7 @@ func (t *WorkerTask) setupSimulator(simulator int, client SimulatorServiceClient
 }
 
 // runSimulation runs the given simulations
-func (t *WorkerTask) runSimulation() error {
+func (t *WorkerTask) runSimulation(ctx context.Context) error {
 	// Run the simulation for the configured duration
 	step := logging.NewStep(t.config.ID, "Run simulation %s", t.config.Simulation)
 	step.Start()
@@ -314,7 +370,7 @@ func (t *WorkerTask) runSimulation() error {
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
-		if err := t.runSimulators(); err != nil {
+		if err := t.runSimulators(ctx); err != nil {
 			errCh <- err
 		}
 		wg.Done()
@@ -334,7 +390,7 @@ func (t *WorkerTask) runSimulation() error {
 }
 
 // runSimulators runs the simulation for a goroutine
-func (t *WorkerTask) runSimulators() error {
+func (t *WorkerTask) runSimulators(ctx context.Context) error {
 	simulators, err := t.getSimulators()
 	if err != nil {
 		return err
@@ -345,7 +401,7 @@ func (t *WorkerTask) runSimulators() error {
 	for i := 0; i < len(simulators); i++ {
 		wg.Add(1)
 		go func(simulator int, client SimulatorServiceClient) {
-			if err := t.runSimulator(simulator, client); err != nil {
+			if err := t.runSimulator(ctx, simulator, client); err != nil {
 				errCh <- err
 			}
 			wg.Done()
@@ -356,16 +412,20 @@ func (t *WorkerTask) runSimulators() error {
 }
 
 // runSimulator runs a random simulator
-func (t *WorkerTask) runSimulator(simulator int, client SimulatorServiceClient) error {
+// The simulator is stopped once the simulation duration has elapsed or the context is canceled.
+func (t *WorkerTask) runSimulator(ctx context.Context, simulator int, client SimulatorServiceClient) error {
 	step := logging.NewStep(t.config.ID, "Run simulator %s/%d", t.config.Simulation, simulator)
 	step.Start()
 
-	if err := t.startSimulator(simulator, client); err != nil {
+	if err := t.startSimulator(ctx, simulator, client); err != nil {
 		step.Fail(err)
 		return err
 	}
 
-	<-time.After(t.config.Duration)
+	select {
+	case <-time.After(t.config.Duration):
+	case <-ctx.Done():
+	}
 
 	if err := t.stopSimulator(simulator, client); err != nil {
 		step.Fail(err)
@@ -375,11 +435,11 @@ func (t *WorkerTask) runSimulator(simulator int, client SimulatorServiceClient)
 }
 
 // startSimulator starts the given simulator
-func (t *WorkerTask) startSimulator(simulator int, client SimulatorServiceClient) error {
+func (t *WorkerTask) startSimulator(ctx context.Context, simulator int, client SimulatorServiceClient) error {
 	request := &SimulatorRequest{
 		Simulation: t.config.Simulation,
 	}
-	_, err := client.StartSimulator(context.Background(), request)
+	_, err := client.StartSimulator(ctx, request)
 	return err
 }
 
@@ -392,7 +452,62 @@ func (t *WorkerTask) stopSimulator(simulator int, client SimulatorServiceClient)
 	return err
 }
 
+// tearDownTimeout is the time allowed for each simulator tear down hook to complete
+const tearDownTimeout = time.Minute
+
+// tearDownSimulators tears down the simulators
+func (t *WorkerTask) tearDownSimulators() {
+	simulators, err := t.getSimulators()
+	if err != nil {
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, simulator := range simulators {
+		wg.Add(1)
+		go func(client SimulatorServiceClient) {
+			ctx, cancel := context.WithTimeout(context.Background(), tearDownTimeout)
+			defer cancel()
+			_, _ = client.TearDownSimulator(ctx, &SimulationLifecycleRequest{
+				Simulation: t.config.Simulation,
+				Args:       t.config.Args,
+			})
+			wg.Done()
+		}(simulator)
+	}
+	wg.Wait()
+}
+
+// tearDownSimulation tears down the simulation
+func (t *WorkerTask) tearDownSimulation() {
+	simulators, err := t.getSimulators()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tearDownTimeout)
+	defer cancel()
+	_, _ = simulators[0].TearDownSimulation(ctx, &SimulationLifecycleRequest{
+		Simulation: t.config.Simulation,
+		Args:       t.config.Args,
+	})
+}
+
+// collectArtifacts collects the artifacts written by each worker
+func (t *WorkerTask) collectArtifacts() {
+	for _, job := range t.jobs {
+		_ = t.runner.CollectArtifacts(job, filepath.Join(t.config.ArtifactsDir, t.config.Simulation, job.ID))
+	}
+}
+
+// releaseArtifacts stops the artifacts container of each worker without collecting its artifacts
+func (t *WorkerTask) releaseArtifacts() {
+	for _, job := range t.jobs {
+		_ = t.runner.ReleaseArtifacts(job)
+	}
+}
+
 // tearDown tears down the job
-func (t *WorkerTask) tearDown() error {
-	return t.runner.DeleteNamespace()
+func (t *WorkerTask) tearDown(failed bool) error {
+	return t.runner.TearDown(t.config.Config, failed)
 }
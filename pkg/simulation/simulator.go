@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/onosproject/helmit/pkg/helm"
+	"github.com/onosproject/helmit/pkg/job"
 	"github.com/onosproject/helmit/pkg/registry"
 	"github.com/onosproject/helmit/pkg/util/logging"
 	"google.golang.org/grpc"
@@ -265,9 +266,11 @@ type simulatorServer struct {
 // Run runs a simulation
 func (s *simulatorServer) Run() error {
 	err := helm.SetContext(&helm.Context{
-		WorkDir:    s.config.Context,
-		Values:     s.config.Values,
-		ValueFiles: s.config.ValueFiles,
+		WorkDir:      s.config.Context,
+		Values:       s.config.Values,
+		ValueFiles:   s.config.ValueFiles,
+		StrictValues: s.config.StrictValues,
+		Driver:       string(s.config.HelmDriver),
 	})
 	if err != nil {
 		return err
@@ -307,6 +310,7 @@ func (s *simulatorServer) SetupSimulation(ctx context.Context, request *Simulati
 	}
 	if err := simulation.setupSimulation(); err != nil {
 		step.Fail(err)
+		job.CaptureReleases()
 		return nil, err
 	}
 	step.Complete()
@@ -342,6 +346,7 @@ func (s *simulatorServer) SetupSimulator(ctx context.Context, request *Simulatio
 	}
 	if err := simulation.setupSimulator(); err != nil {
 		step.Fail(err)
+		job.CaptureReleases()
 		return nil, err
 	}
 	step.Complete()
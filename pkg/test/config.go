@@ -17,6 +17,7 @@ package test
 import (
 	"github.com/onosproject/helmit/pkg/job"
 	"os"
+	"time"
 )
 
 type testType string
@@ -33,11 +34,24 @@ const (
 
 // Config is a test configuration
 type Config struct {
-	*job.Config `json:",inline"`
-	Suites      []string `json:"suites,omitempty"`
-	Tests       []string `json:"tests,omitempty"`
-	Iterations  int      `json:"iterations,omitempty"`
-	Verbose     bool     `json:"verbose,omitempty"`
+	*job.Config        `json:",inline"`
+	Suites             []string                 `json:"suites,omitempty"`
+	Tests              []string                 `json:"tests,omitempty"`
+	Tags               []string                 `json:"tags,omitempty"`
+	SkipTags           []string                 `json:"skipTags,omitempty"`
+	Iterations         int                      `json:"iterations,omitempty"`
+	Workers            int                      `json:"workers,omitempty"`
+	Durations          map[string]time.Duration `json:"durations,omitempty"`
+	Matrix             []MatrixEntry            `json:"matrix,omitempty"`
+	MatrixEntry        *MatrixEntry             `json:"matrixEntry,omitempty"`
+	SuiteTimeout       time.Duration            `json:"suiteTimeout,omitempty"`
+	TestTimeout        time.Duration            `json:"testTimeout,omitempty"`
+	Shuffle            bool                     `json:"shuffle,omitempty"`
+	Seed               int64                    `json:"seed,omitempty"`
+	Retries            int                      `json:"retries,omitempty"`
+	RetrySameNamespace bool                     `json:"retrySameNamespace,omitempty"`
+	Verbose            bool                     `json:"verbose,omitempty"`
+	Reports            []Report                 `json:"-"`
 }
 
 // getTestContext returns the current test context
@@ -16,14 +16,21 @@ package test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/onosproject/helmit/pkg/job"
 	"github.com/onosproject/helmit/pkg/kubernetes"
 	"github.com/onosproject/helmit/pkg/registry"
 	"google.golang.org/grpc"
+	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"sync"
+	"time"
 )
 
 // newCoordinator returns a new test coordinator
@@ -41,87 +48,343 @@ type Coordinator struct {
 
 // Run runs the tests
 func (c *Coordinator) Run() error {
-	for iteration := 1; iteration <= c.config.Iterations || c.config.Iterations < 0; iteration++ {
-		suites := c.config.Suites
-		if len(suites) == 0 || suites[0] == "" {
-			suites = registry.GetTestSuites()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := job.OnCancel(cancel)
+	defer stop()
+
+	result, err := c.run(ctx)
+	job.PrintRetainedNamespaces(os.Stdout)
+	if ctx.Err() != nil {
+		return job.ErrCanceled
+	}
+
+	// Write and print the results of the suites that completed even if the run failed
+	if result != nil {
+		if err := writeResult(result); err != nil {
+			fmt.Println(err)
 		}
-		workers := make([]*WorkerTask, len(suites))
-		for i, suite := range suites {
-			jobID := newJobID(c.config.ID+"-"+strconv.Itoa(iteration), suite)
-			env := c.config.Env
-			if env == nil {
-				env = make(map[string]string)
-			}
-			env[testTypeEnv] = string(testTypeWorker)
-			config := &Config{
-				Config: &job.Config{
-					ID:              jobID,
-					Image:           c.config.Config.Image,
-					ImagePullPolicy: c.config.Config.ImagePullPolicy,
-					Executable:      c.config.Config.Executable,
-					Context:         c.config.Config.Context,
-					Values:          c.config.Config.Values,
-					ValueFiles:      c.config.Config.ValueFiles,
-					Env:             env,
-					Timeout:         c.config.Config.Timeout,
-				},
-				Suites:     []string{suite},
-				Tests:      c.config.Tests,
-				Iterations: c.config.Iterations,
+		result.Print(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Failed() {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// run runs the tests and returns the aggregate result of all the suites
+// Iterations stop once an iteration fails. If a worker fails, the partial result is returned with the error.
+func (c *Coordinator) run(ctx context.Context) (*Result, error) {
+	suites, err := filterSuites(registry.GetTestSuites(), c.config.Suites, c.config.Tags, c.config.SkipTags)
+	if err != nil {
+		return nil, err
+	} else if len(suites) == 0 {
+		return nil, errors.New("no test suites matched the given suites and tags")
+	}
+
+	runs := c.getSuiteRuns(suites)
+	result := &Result{}
+	var random *rand.Rand
+	if c.config.Shuffle {
+		fmt.Printf("Shuffling suites and tests with seed %d\n", c.config.Seed)
+		random = rand.New(rand.NewSource(c.config.Seed))
+		result.Seed = &c.config.Seed
+	}
+	for iteration := 1; (iteration <= c.config.Iterations || c.config.Iterations < 0) && ctx.Err() == nil; iteration++ {
+		if random != nil {
+			random.Shuffle(len(runs), func(i, j int) {
+				runs[i], runs[j] = runs[j], runs[i]
+			})
+		}
+		var results []*SuiteResult
+		if c.config.Workers > 0 {
+			results, err = c.runShards(ctx, runs, iteration)
+		} else {
+			workers := make([]*WorkerTask, len(runs))
+			for i, run := range runs {
+				config := c.newWorkerConfig(run, iteration)
+				workers[i] = &WorkerTask{
+					coordinator:  c,
+					runner:       job.NewNamespace(config.ID, config.RBAC),
+					config:       config,
+					iteration:    iteration,
+					artifactsDir: c.getArtifactsDir(run, iteration),
+				}
 			}
-			worker := &WorkerTask{
-				runner: job.NewNamespace(config.ID),
-				config: config,
+			results, err = runWorkers(ctx, workers)
+		}
+		result.Suites = append(result.Suites, results...)
+		if err != nil {
+			return result, err
+		}
+		if result.Failed() {
+			break
+		}
+	}
+	return result, nil
+}
+
+// suiteRun is a run of a suite with an optional matrix entry
+type suiteRun struct {
+	suite  string
+	matrix *MatrixEntry
+}
+
+// label returns the label identifying the run in results
+func (r suiteRun) label() string {
+	if r.matrix == nil {
+		return r.suite
+	}
+	return getSuiteLabel(r.suite, r.matrix.Name)
+}
+
+// getSuiteRuns returns a run of each suite for each matrix entry
+func (c *Coordinator) getSuiteRuns(suites []string) []suiteRun {
+	if len(c.config.Matrix) == 0 {
+		runs := make([]suiteRun, len(suites))
+		for i, suite := range suites {
+			runs[i] = suiteRun{suite: suite}
+		}
+		return runs
+	}
+
+	runs := make([]suiteRun, 0, len(suites)*len(c.config.Matrix))
+	for _, suite := range suites {
+		for i := range c.config.Matrix {
+			runs = append(runs, suiteRun{
+				suite:  suite,
+				matrix: &c.config.Matrix[i],
+			})
+		}
+	}
+	return runs
+}
+
+// getSuiteRun returns the run for the given suite result
+func (c *Coordinator) getSuiteRun(result *SuiteResult) suiteRun {
+	run := suiteRun{suite: result.Suite}
+	for i, entry := range c.config.Matrix {
+		if entry.Name == result.Matrix {
+			run.matrix = &c.config.Matrix[i]
+		}
+	}
+	return run
+}
+
+// newWorkerConfig returns the configuration for the worker job running the given suite
+func (c *Coordinator) newWorkerConfig(run suiteRun, iteration int) *Config {
+	testID := c.config.ID + "-" + strconv.Itoa(iteration)
+	if run.matrix != nil {
+		testID = testID + "-" + run.matrix.Name
+	}
+	jobID := newJobID(testID, run.suite)
+	// Copy the environment since worker configs are created concurrently by shards and retries
+	env := make(map[string]string, len(c.config.Env)+1)
+	for key, value := range c.config.Env {
+		env[key] = value
+	}
+	env[testTypeEnv] = string(testTypeWorker)
+	return &Config{
+		Config: &job.Config{
+			ID:              jobID,
+			Image:           c.config.Config.Image,
+			ImagePullPolicy: c.config.Config.ImagePullPolicy,
+			Executable:      c.config.Config.Executable,
+			Context:         c.config.Config.Context,
+			Values:          c.config.Config.Values,
+			ValueFiles:      c.config.Config.ValueFiles,
+			StrictValues:    c.config.Config.StrictValues,
+			HelmDriver:      c.config.Config.HelmDriver,
+			Env:             env,
+			Timeout:         c.config.Config.Timeout,
+			PodTemplate:     c.config.Config.PodTemplate,
+			NoTeardown:      c.config.Config.NoTeardown,
+			KeepOnFailure:   c.config.Config.KeepOnFailure,
+			ArtifactsDir:    c.config.Config.ArtifactsDir,
+			RBAC:            c.config.Config.RBAC.WithSuite(registry.GetTestSuite(run.suite)),
+		},
+		Suites:       []string{run.suite},
+		Tests:        c.config.Tests,
+		Iterations:   c.config.Iterations,
+		MatrixEntry:  run.matrix,
+		SuiteTimeout: c.config.SuiteTimeout,
+		TestTimeout:  c.config.TestTimeout,
+		Shuffle:      c.config.Shuffle,
+		Seed:         c.config.Seed,
+	}
+}
+
+// getArtifactsDir returns the directory to which to copy the artifacts of the given suite
+func (c *Coordinator) getArtifactsDir(run suiteRun, iteration int) string {
+	artifactsDir := filepath.Join(c.config.ArtifactsDir, run.suite)
+	if run.matrix != nil {
+		artifactsDir = filepath.Join(artifactsDir, run.matrix.Name)
+	}
+	if c.config.Iterations != 1 {
+		artifactsDir = filepath.Join(artifactsDir, strconv.Itoa(iteration))
+	}
+	return artifactsDir
+}
+
+// runShards runs the suites on a fixed pool of worker namespaces
+// Each namespace takes suites from a shared queue and runs them one at a time. The queue is ordered longest first by
+// the suites' historic durations, if known, to balance the suites across the namespaces.
+func (c *Coordinator) runShards(ctx context.Context, runs []suiteRun, iteration int) ([]*SuiteResult, error) {
+	queue := make(chan suiteRun, len(runs))
+	for _, run := range orderSuites(runs, c.config.Durations) {
+		queue <- run
+	}
+	close(queue)
+
+	// Each namespace is granted the rules required by any of the suites it may run
+	rbac := c.config.RBAC
+	for _, run := range runs {
+		rbac = rbac.WithSuite(registry.GetTestSuite(run.suite))
+	}
+
+	workers := c.config.Workers
+	if workers > len(runs) {
+		workers = len(runs)
+	}
+
+	wg := &sync.WaitGroup{}
+	mu := &sync.Mutex{}
+	errChan := make(chan error, workers)
+	results := make([]*SuiteResult, 0, len(runs))
+	for i := 1; i <= workers; i++ {
+		shard := &ShardTask{
+			coordinator: c,
+			runner:      job.NewNamespace(fmt.Sprintf("%s-%d-shard-%d", c.config.ID, iteration, i), rbac),
+			iteration:   iteration,
+		}
+		wg.Add(1)
+		go func(shard *ShardTask) {
+			shardResults, err := shard.Run(ctx, queue)
+			mu.Lock()
+			results = append(results, shardResults...)
+			mu.Unlock()
+			if err != nil {
+				errChan <- err
 			}
-			workers[i] = worker
+			wg.Done()
+		}(shard)
+	}
+	wg.Wait()
+	close(errChan)
+
+	// Order the results by suite regardless of the order in which they were run
+	indexes := make(map[string]int)
+	for i, run := range runs {
+		indexes[run.label()] = i
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return indexes[results[i].Label()] < indexes[results[j].Label()]
+	})
+	for err := range errChan {
+		return results, err
+	}
+	return results, nil
+}
+
+// retrySuite reruns the failed tests of the suite until they pass or the configured retries are exhausted
+// Retries run in the given namespace if configured to, otherwise each retry runs in a fresh namespace. The results
+// of the retries are merged into the suite's result.
+func (c *Coordinator) retrySuite(ctx context.Context, runner *job.Runner, result *SuiteResult) error {
+	for attempt := 1; attempt <= c.config.Retries && result.Failed() && ctx.Err() == nil; attempt++ {
+		task := c.newRetryTask(runner, result, attempt)
+		var retry *SuiteResult
+		var err error
+		if task.runner != nil {
+			retry, err = task.runSuite(ctx)
+		} else {
+			task.runner = job.NewNamespace(task.config.ID, task.config.RBAC)
+			retry, err = task.Run(ctx)
 		}
-		err := runWorkers(workers)
 		if err != nil {
 			return err
 		}
+		result.addRetry(retry)
 	}
 	return nil
 }
 
-// runWorkers runs the given test workers
-func runWorkers(tasks []*WorkerTask) error {
+// newRetryTask returns a task rerunning the failed tests of the suite
+// If retries run in the same namespace, the task reuses the given runner and resets the namespace before running,
+// deleting the releases installed by the failed attempt. Otherwise, the task has no runner.
+func (c *Coordinator) newRetryTask(runner *job.Runner, result *SuiteResult, attempt int) *WorkerTask {
+	run := c.getSuiteRun(result)
+	config := c.newWorkerConfig(run, result.Iteration)
+	config.ID = fmt.Sprintf("%s-retry-%d", config.ID, attempt)
+	if tests := result.failedTests(); len(tests) > 0 {
+		config.Tests = make([]string, len(tests))
+		for i, test := range tests {
+			config.Tests[i] = "^" + regexp.QuoteMeta(test) + "$"
+		}
+	}
+
+	task := &WorkerTask{
+		config:       config,
+		iteration:    result.Iteration,
+		artifactsDir: filepath.Join(c.getArtifactsDir(run, result.Iteration), fmt.Sprintf("retry-%d", attempt)),
+	}
+	if c.config.RetrySameNamespace {
+		task.runner = runner
+		task.reset = true
+	}
+	return task
+}
+
+// orderSuites orders the suite runs longest first by their historic durations
+// Runs with no known duration are ordered first.
+func orderSuites(runs []suiteRun, durations map[string]time.Duration) []suiteRun {
+	ordered := append([]suiteRun{}, runs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		di, iok := durations[ordered[i].label()]
+		dj, jok := durations[ordered[j].label()]
+		if iok != jok {
+			return !iok
+		}
+		return di > dj
+	})
+	return ordered
+}
+
+// runWorkers runs the given test workers and returns the results of the suites they ran
+func runWorkers(ctx context.Context, tasks []*WorkerTask) ([]*SuiteResult, error) {
 	// Start jobs in separate goroutines
 	wg := &sync.WaitGroup{}
 	errChan := make(chan error, len(tasks))
-	codeChan := make(chan int, len(tasks))
-	for _, task := range tasks {
+	results := make([]*SuiteResult, len(tasks))
+	for i, task := range tasks {
 		wg.Add(1)
-		go func(task *WorkerTask) {
-			status, err := task.Run()
+		go func(i int, task *WorkerTask) {
+			result, err := task.Run(ctx)
 			if err != nil {
 				errChan <- err
-			} else {
-				codeChan <- status
 			}
+			results[i] = result
 			wg.Done()
-		}(task)
+		}(i, task)
 	}
 
-	// Wait for all jobs to start before proceeding
-	go func() {
-		wg.Wait()
-		close(errChan)
-		close(codeChan)
-	}()
+	// Wait for all jobs to complete before proceeding
+	wg.Wait()
+	close(errChan)
 
 	// If any job returned an error, return it
-	for err := range errChan {
-		return err
-	}
-
-	// If any job returned a non-zero exit code, exit with it
-	for code := range codeChan {
-		if code != 0 {
-			os.Exit(code)
+	suites := make([]*SuiteResult, 0, len(results))
+	for _, result := range results {
+		if result != nil {
+			suites = append(suites, result)
 		}
 	}
-	return nil
+	for err := range errChan {
+		return suites, err
+	}
+	return suites, nil
 }
 
 // newJobID returns a new unique test job ID
@@ -129,44 +392,208 @@ func newJobID(testID, suite string) string {
 	return fmt.Sprintf("%s-%s", testID, suite)
 }
 
+// ShardTask manages a worker namespace in which suites are run one at a time
+type ShardTask struct {
+	coordinator *Coordinator
+	runner      *job.Runner
+	iteration   int
+}
+
+// Run runs suites from the queue in the shard's namespace until the queue is empty
+// If the context is canceled, the running suite is stopped and the namespace torn down unless teardown is disabled.
+func (s *ShardTask) Run(ctx context.Context, queue <-chan suiteRun) ([]*SuiteResult, error) {
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.runner.Cancel()
+		case <-doneCh:
+		}
+	}()
+	results, err := s.run(ctx, queue)
+	close(doneCh)
+	failed := ctx.Err() == nil && err != nil
+	for _, result := range results {
+		failed = failed || result.Failed()
+	}
+	_ = s.runner.TearDown(s.coordinator.config.Config, failed)
+	return results, err
+}
+
+// run creates the shard's namespace and runs suites from the queue
+// The namespace is reset before each suite after the first to delete the releases installed by the previous suite.
+func (s *ShardTask) run(ctx context.Context, queue <-chan suiteRun) ([]*SuiteResult, error) {
+	if err := s.runner.CreateNamespace(); err != nil {
+		return nil, err
+	}
+
+	var results []*SuiteResult
+	for run := range queue {
+		if ctx.Err() != nil {
+			return results, nil
+		}
+		task := &WorkerTask{
+			runner:       s.runner,
+			config:       s.coordinator.newWorkerConfig(run, s.iteration),
+			iteration:    s.iteration,
+			artifactsDir: s.coordinator.getArtifactsDir(run, s.iteration),
+			reset:        len(results) > 0,
+		}
+		result, err := task.runSuite(ctx)
+		if err == nil {
+			err = s.coordinator.retrySuite(ctx, s.runner, result)
+		}
+		if err != nil {
+			return append(results, task.failResult(result, err)), err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 // WorkerTask manages a single test job for a test worker
 type WorkerTask struct {
-	runner *job.Runner
-	config *Config
+	coordinator  *Coordinator
+	runner       *job.Runner
+	config       *Config
+	iteration    int
+	artifactsDir string
+	reset        bool
+	job          *job.Job
+}
+
+// Run runs the worker job in its own namespace
+// If the context is canceled, the worker is stopped and its namespace torn down unless teardown is disabled. A result
+// is returned even if the worker fails, with the suite marked failed by the error.
+func (t *WorkerTask) Run(ctx context.Context) (*SuiteResult, error) {
+	doneCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.runner.Cancel()
+		case <-doneCh:
+		}
+	}()
+	result, err := t.run(ctx)
+	if err == nil && t.coordinator != nil {
+		err = t.coordinator.retrySuite(ctx, t.runner, result)
+	}
+	close(doneCh)
+	if err != nil {
+		result = t.failResult(result, err)
+	}
+	failed := ctx.Err() == nil && result.Failed()
+	_ = t.runner.TearDown(t.config.Config, failed)
+	return result, err
+}
+
+// failResult marks the suite's result failed by the given error, creating the result if the suite did not run
+func (t *WorkerTask) failResult(result *SuiteResult, err error) *SuiteResult {
+	if result == nil {
+		result = t.newResult()
+	}
+	result.fail(err)
+	return result
+}
+
+// newResult returns a new result for the task's suite
+func (t *WorkerTask) newResult() *SuiteResult {
+	result := newSuiteResult(t.config.Suites[0], t.iteration)
+	if t.config.MatrixEntry != nil {
+		result.Matrix = t.config.MatrixEntry.Name
+	}
+	return result
 }
 
-// Run runs the worker job
-func (t *WorkerTask) Run() (int, error) {
+// run creates the worker's namespace and runs the suite
+func (t *WorkerTask) run(ctx context.Context) (*SuiteResult, error) {
 	if err := t.runner.CreateNamespace(); err != nil {
-		return 0, err
+		return nil, err
 	}
+	return t.runSuite(ctx)
+}
+
+// runSuite runs the suite's worker job in the task's namespace
+// If the task reuses a namespace, the objects left by previous jobs are deleted first. If the suite fails,
+// diagnostics are captured before the job's artifacts are collected. If the job fails, its artifacts container is
+// stopped without collecting the artifacts.
+func (t *WorkerTask) runSuite(ctx context.Context) (*SuiteResult, error) {
+	if t.reset {
+		if err := t.runner.ResetNamespace(); err != nil {
+			return nil, err
+		}
+	}
+	result, err := t.runJob(ctx)
+	failed := ctx.Err() == nil && (err != nil || result.Failed())
+	if failed && t.config.ArtifactsDir != "" {
+		_ = t.runner.CaptureDiagnostics(filepath.Join(t.artifactsDir, job.DiagnosticsDir))
+	}
+	if err == nil {
+		_ = t.runner.CollectArtifacts(t.job, t.artifactsDir)
+	} else if t.job != nil {
+		_ = t.runner.ReleaseArtifacts(t.job)
+	}
+	return result, err
+}
 
+// runJob runs the worker job and returns the result of the suite
+func (t *WorkerTask) runJob(ctx context.Context) (*SuiteResult, error) {
 	job := &job.Job{
 		Config:    t.config.Config,
 		JobConfig: t.config,
 		Type:      testJobType,
 	}
+	t.job = job
 
 	err := t.runner.StartJob(job)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	address := fmt.Sprintf("%s.%s.svc.cluster.local:5000", job.ID, job.ID)
+	address := fmt.Sprintf("%s.%s.svc.cluster.local:5000", job.ID, t.runner.Namespace())
 	conn, err := grpc.Dial(address, grpc.WithInsecure())
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	defer conn.Close()
+
+	result := t.newResult()
 	client := NewWorkerServiceClient(conn)
-	_, err = client.RunTests(context.Background(), &TestRequest{
-		Suite: t.config.Suites[0],
-		Tests: t.config.Tests,
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	stream, err := client.RunTests(streamCtx, &TestRequest{
+		Suite:        t.config.Suites[0],
+		Tests:        t.config.Tests,
+		SuiteTimeout: t.config.SuiteTimeout,
+		TestTimeout:  t.config.TestTimeout,
+		Shuffle:      t.config.Shuffle,
+		Seed:         t.config.Seed,
 	})
+	if err == nil {
+		err = receiveEvents(stream, result)
+	}
+	cancelStream()
 
-	status, err := t.runner.WaitForExit(job)
+	status, exitErr := t.runner.WaitForExit(job)
+	if exitErr != nil {
+		return nil, exitErr
+	}
 	if err != nil {
-		return 0, err
+		result.fail(err)
+	} else if status != 0 && !result.Failed() {
+		result.fail(fmt.Errorf("worker exited with status %d", status))
+	}
+	return result, nil
+}
+
+// receiveEvents adds the events received from the given stream to the result until the worker ends the stream
+func receiveEvents(stream WorkerService_RunTestsClient, result *SuiteResult) error {
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		result.handleEvent(event)
 	}
-	_ = t.runner.DeleteNamespace()
-	return status, err
 }
@@ -0,0 +1,143 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"errors"
+	"github.com/onosproject/helmit/pkg/job"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrderSuites(t *testing.T) {
+	raft := &MatrixEntry{Name: "raft"}
+	runs := []suiteRun{{suite: "a"}, {suite: "b"}, {suite: "c"}, {suite: "d"}, {suite: "a", matrix: raft}}
+	durations := map[string]time.Duration{
+		"a":       time.Minute,
+		"b":       time.Hour,
+		"d":       time.Second,
+		"a[raft]": time.Minute * 2,
+	}
+	assert.Equal(t, []suiteRun{runs[2], runs[1], runs[4], runs[0], runs[3]}, orderSuites(runs, durations))
+	assert.Equal(t, runs, orderSuites(runs, nil))
+}
+
+func TestGetSuiteRuns(t *testing.T) {
+	coordinator := &Coordinator{config: &Config{}}
+	runs := coordinator.getSuiteRuns([]string{"a", "b"})
+	assert.Len(t, runs, 2)
+	assert.Equal(t, "a", runs[0].label())
+
+	coordinator.config.Matrix = []MatrixEntry{{Name: "raft"}, {Name: "gossip"}}
+	runs = coordinator.getSuiteRuns([]string{"a", "b"})
+	assert.Len(t, runs, 4)
+	assert.Equal(t, "a[raft]", runs[0].label())
+	assert.Equal(t, "a[gossip]", runs[1].label())
+	assert.Equal(t, "b[raft]", runs[2].label())
+
+	run := coordinator.getSuiteRun(&SuiteResult{Suite: "b", Matrix: "gossip"})
+	assert.Equal(t, "b[gossip]", run.label())
+}
+
+func TestNewWorkerConfigConcurrently(t *testing.T) {
+	coordinator := &Coordinator{
+		config: &Config{
+			Config: &job.Config{
+				ID:  "test",
+				Env: map[string]string{"FOO": "bar"},
+			},
+		},
+	}
+
+	// Shards and retries create worker configs concurrently
+	wg := &sync.WaitGroup{}
+	configs := make([]*Config, 10)
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			configs[i] = coordinator.newWorkerConfig(suiteRun{suite: "suite"}, i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, config := range configs {
+		assert.Equal(t, map[string]string{"FOO": "bar", testTypeEnv: string(testTypeWorker)}, config.Env)
+	}
+	assert.Len(t, coordinator.config.Env, 1)
+	configs[0].Env["FOO"] = "baz"
+	assert.Equal(t, "bar", configs[1].Env["FOO"])
+}
+
+func TestFailResult(t *testing.T) {
+	task := &WorkerTask{
+		config: &Config{
+			Config:      &job.Config{},
+			Suites:      []string{"suite"},
+			MatrixEntry: &MatrixEntry{Name: "raft"},
+		},
+		iteration: 2,
+	}
+
+	// Suites whose workers fail before reporting a result are still included in the results
+	result := task.failResult(nil, errors.New("pod failed"))
+	assert.Equal(t, "suite[raft]", result.Label())
+	assert.Equal(t, 2, result.Iteration)
+	assert.True(t, result.Failed())
+	assert.Equal(t, "pod failed", result.Error)
+
+	result = newSuiteResult("suite", 1)
+	result.handleEvent(&TestEvent{Type: TestEventType_START, Test: "TestFoo"})
+	assert.Same(t, result, task.failResult(result, errors.New("stream closed")))
+	assert.True(t, result.Failed())
+	assert.Equal(t, StatusFailed, result.Tests[0].Status)
+}
+
+func TestNewRetryTask(t *testing.T) {
+	coordinator := &Coordinator{
+		config: &Config{
+			Config:       &job.Config{ID: "test", ArtifactsDir: "artifacts"},
+			Matrix:       []MatrixEntry{{Name: "raft"}},
+			Iterations:   1,
+			Retries:      2,
+			SuiteTimeout: time.Minute,
+		},
+	}
+	result := newSuiteResult("suite", 1)
+	result.Matrix = "raft"
+	result.handleEvent(&TestEvent{Type: TestEventType_START})
+	result.handleEvent(&TestEvent{Type: TestEventType_FAIL, Test: "TestFoo/bar"})
+	result.handleEvent(&TestEvent{Type: TestEventType_FAIL})
+
+	// Retries in a fresh namespace leave the runner to be created for the retry's own namespace
+	task := coordinator.newRetryTask(nil, result, 1)
+	assert.Nil(t, task.runner)
+	assert.False(t, task.reset)
+	assert.Equal(t, "test-1-raft-suite-retry-1", task.config.ID)
+	assert.Equal(t, []string{`^TestFoo/bar$`}, task.config.Tests)
+	assert.Equal(t, &coordinator.config.Matrix[0], task.config.MatrixEntry)
+	assert.Equal(t, "artifacts/suite/raft/retry-1", task.artifactsDir)
+
+	// Retries in the same namespace reuse its runner and reset the namespace before running
+	coordinator.config.RetrySameNamespace = true
+	runner := &job.Runner{}
+	task = coordinator.newRetryTask(runner, result, 2)
+	assert.Same(t, runner, task.runner)
+	assert.True(t, task.reset)
+	assert.Equal(t, "test-1-raft-suite-retry-2", task.config.ID)
+	assert.Equal(t, "artifacts/suite/raft/retry-2", task.artifactsDir)
+}
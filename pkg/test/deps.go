@@ -0,0 +1,63 @@
+// Copyright 2019-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"time"
+)
+
+// errUnsupported is returned by testDeps for testing features the worker does not support
+var errUnsupported = errors.New("testing: unsupported by the test worker")
+
+// corpusEntry is the type of the fuzzing corpus entries passed between the testing package and its dependencies
+type corpusEntry = struct {
+	Parent     string
+	Path       string
+	Data       []byte
+	Values     []interface{}
+	Generation int
+	IsSeed     bool
+}
+
+// testDeps provides the dependencies of the testing package's test runner to the worker
+// Like the dependencies used by testing.Main, only matching of test names is supported; profiling, test logs,
+// coverage and fuzzing are not.
+type testDeps struct{}
+
+func (testDeps) MatchString(pat, str string) (bool, error)   { return true, nil }
+func (testDeps) StartCPUProfile(io.Writer) error             { return errUnsupported }
+func (testDeps) StopCPUProfile()                             {}
+func (testDeps) WriteProfileTo(string, io.Writer, int) error { return errUnsupported }
+func (testDeps) ModulePath() string                          { return "" }
+func (testDeps) ImportPath() string                          { return "" }
+func (testDeps) StartTestLog(io.Writer)                      {}
+func (testDeps) StopTestLog() error                          { return errUnsupported }
+func (testDeps) SetPanicOnExit0(bool)                        {}
+func (testDeps) CoordinateFuzzing(time.Duration, int64, time.Duration, int64, int, []corpusEntry, []reflect.Type, string, string) error {
+	return errUnsupported
+}
+func (testDeps) RunFuzzWorker(func(corpusEntry) error) error { return errUnsupported }
+func (testDeps) ReadCorpus(string, []reflect.Type) ([]corpusEntry, error) {
+	return nil, errUnsupported
+}
+func (testDeps) CheckCorpus([]interface{}, []reflect.Type) error { return nil }
+func (testDeps) ResetCoverage()                                  {}
+func (testDeps) SnapshotCoverage()                               {}
+func (testDeps) InitRuntimeCoverage() (mode string, tearDown func(string, string) (string, error), snapcov func() float64) {
+	return
+}
@@ -0,0 +1,139 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventStream is the stream to which the worker reports test events
+var eventStream WorkerService_RunTestsServer
+var eventStreamMu sync.Mutex
+
+// setEventStream sets the stream to which test events are reported
+func setEventStream(stream WorkerService_RunTestsServer) {
+	eventStreamMu.Lock()
+	defer eventStreamMu.Unlock()
+	eventStream = stream
+}
+
+// reportEvent reports a test event to the coordinator if the tests are being run by a worker
+func reportEvent(event *TestEvent) {
+	eventStreamMu.Lock()
+	defer eventStreamMu.Unlock()
+	if eventStream == nil {
+		return
+	}
+	if err := eventStream.Send(event); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// reportResult reports the result of the given test once it has completed
+func reportResult(t *testing.T, name string, elapsed time.Duration) {
+	reportEvent(newResultEvent(t, name, elapsed))
+}
+
+// newResultEvent returns an event for the result of the given test
+func newResultEvent(t *testing.T, name string, elapsed time.Duration) *TestEvent {
+	eventType := TestEventType_PASS
+	if t.Failed() {
+		eventType = TestEventType_FAIL
+	} else if t.Skipped() {
+		eventType = TestEventType_SKIP
+	}
+	return &TestEvent{
+		Type:    eventType,
+		Test:    name,
+		Elapsed: elapsed,
+	}
+}
+
+// captureOutput reports each line written to stdout as an output event until the returned function is called
+// Lines are still written to the original stdout so they appear in the worker's logs.
+func captureOutput() (func(), error) {
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	os.Stdout = writer
+
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		buf := bufio.NewReader(reader)
+		test := ""
+		for {
+			line, err := buf.ReadString('\n')
+			if line != "" {
+				fmt.Fprint(stdout, line)
+				line = strings.TrimSuffix(line, "\n")
+				if name, ok := parseTestName(line); ok {
+					test = name
+				}
+				reportEvent(&TestEvent{
+					Type:   TestEventType_OUTPUT,
+					Test:   test,
+					Output: line,
+				})
+			}
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+		}
+	}()
+	return func() {
+		os.Stdout = stdout
+		writer.Close()
+		<-doneCh
+		reader.Close()
+	}, nil
+}
+
+// testMarkers are the prefixes of lines written by the testing package when a test's output begins
+var testMarkers = []string{"=== RUN", "=== CONT", "=== PAUSE", "=== NAME", "--- PASS:", "--- FAIL:", "--- SKIP:"}
+
+// parseTestName returns the name of the test method to which subsequent lines of verbose test output belong
+// Test names in the output are of the form <suite>/<method>[/<subtest>...]; an empty name is returned for
+// lines that mark the output of the suite itself.
+func parseTestName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	for _, marker := range testMarkers {
+		if !strings.HasPrefix(line, marker) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, marker))
+		if len(fields) == 0 {
+			return "", false
+		}
+		names := strings.Split(fields[0], "/")
+		if len(names) < 2 {
+			return "", true
+		}
+		return names[1], true
+	}
+	return "", false
+}
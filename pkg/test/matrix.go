@@ -0,0 +1,164 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+// MatrixEntry is a named set of chart values against which to run each suite
+type MatrixEntry struct {
+	// Name is the name of the matrix entry
+	Name string `json:"name"`
+	// Values is a mapping of release names to the chart values to apply to each release
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+// ParseMatrix parses matrix entries in the format {name}={file}, where the file contains the values for each release
+// keyed by release name, or the path to a matrix file containing the values for each entry keyed by entry name
+func ParseMatrix(matrix []string) ([]MatrixEntry, error) {
+	var entries []MatrixEntry
+	names := make(map[string]bool)
+	for _, arg := range matrix {
+		var parsed []MatrixEntry
+		if index := strings.Index(arg, "="); index != -1 {
+			values, err := readMatrixValues(arg[index+1:])
+			if err != nil {
+				return nil, err
+			}
+			entry, err := newMatrixEntry(arg[:index], values)
+			if err != nil {
+				return nil, err
+			}
+			parsed = []MatrixEntry{entry}
+		} else {
+			fileEntries, err := readMatrixFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			parsed = fileEntries
+		}
+
+		for _, entry := range parsed {
+			if errs := validation.IsDNS1123Label(entry.Name); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid matrix entry name %s: %s", entry.Name, strings.Join(errs, ", "))
+			}
+			if names[entry.Name] {
+				return nil, fmt.Errorf("duplicate matrix entry %s", entry.Name)
+			}
+			names[entry.Name] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readMatrixFile reads a matrix file containing the values for each matrix entry keyed by entry name
+// Entries are returned sorted by name.
+func readMatrixFile(file string) ([]MatrixEntry, error) {
+	values, err := readMatrixValues(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("matrix file %s defines no entries", file)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]MatrixEntry, len(names))
+	for i, name := range names {
+		entryValues, ok := values[name].(map[string]interface{})
+		if !ok && values[name] != nil {
+			return nil, fmt.Errorf("matrix entry %s in %s must be a mapping of release values", name, file)
+		}
+		entry, err := newMatrixEntry(name, entryValues)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// readMatrixValues reads a YAML file into a map
+func readMatrixValues(file string) (map[string]interface{}, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(bytes, &values); err != nil {
+		return nil, fmt.Errorf("invalid matrix values %s: %v", file, err)
+	}
+	return values, nil
+}
+
+// newMatrixEntry returns a new matrix entry, verifying the values of each release are a map
+func newMatrixEntry(name string, values map[string]interface{}) (MatrixEntry, error) {
+	if name == "" {
+		return MatrixEntry{}, errors.New("matrix entries must be in the format {name}={file}")
+	}
+	for release, releaseValues := range values {
+		if _, ok := releaseValues.(map[string]interface{}); !ok && releaseValues != nil {
+			return MatrixEntry{}, fmt.Errorf("values for release %s in matrix entry %s must be a map", release, name)
+		}
+	}
+	return MatrixEntry{
+		Name:   name,
+		Values: values,
+	}, nil
+}
+
+// writeValueFiles writes the values of each release in the matrix entry to a file in the given directory
+// The returned value files are appended to the given value files for each release, so the matrix entry's values
+// override the values in the release's other value files.
+func (e *MatrixEntry) writeValueFiles(dir string, valueFiles map[string][]string) (map[string][]string, error) {
+	files := make(map[string][]string)
+	for release, releaseFiles := range valueFiles {
+		files[release] = releaseFiles
+	}
+	for release, values := range e.Values {
+		bytes, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		file := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", e.Name, release))
+		if err := ioutil.WriteFile(file, bytes, 0644); err != nil {
+			return nil, err
+		}
+		files[release] = append(append([]string{}, files[release]...), file)
+	}
+	return files, nil
+}
+
+// getSuiteLabel returns the label identifying a run of the suite with the given matrix entry
+func getSuiteLabel(suite string, matrix string) string {
+	if matrix == "" {
+		return suite
+	}
+	return fmt.Sprintf("%s[%s]", suite, matrix)
+}
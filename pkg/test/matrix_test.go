@@ -0,0 +1,60 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "matrix")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, "replicas.yaml")
+	assert.NoError(t, ioutil.WriteFile(valuesFile, []byte("atomix:\n  replicas: 3\n"), 0644))
+	matrixFile := filepath.Join(dir, "matrix.yaml")
+	assert.NoError(t, ioutil.WriteFile(matrixFile, []byte("raft:\n  atomix:\n    backend: raft\ngossip:\n  atomix:\n    backend: gossip\n"), 0644))
+
+	entries, err := ParseMatrix([]string{"three-replicas=" + valuesFile, matrixFile})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "three-replicas", entries[0].Name)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(3)}, entries[0].Values["atomix"])
+	assert.Equal(t, "gossip", entries[1].Name)
+	assert.Equal(t, "raft", entries[2].Name)
+
+	_, err = ParseMatrix([]string{"raft=" + valuesFile, matrixFile})
+	assert.Error(t, err)
+	_, err = ParseMatrix([]string{"Three_Replicas=" + valuesFile})
+	assert.Error(t, err)
+	_, err = ParseMatrix([]string{"=" + valuesFile})
+	assert.Error(t, err)
+
+	valueFiles, err := entries[2].writeValueFiles(dir, map[string][]string{"atomix": {valuesFile}})
+	assert.NoError(t, err)
+	assert.Len(t, valueFiles["atomix"], 2)
+	assert.Equal(t, valuesFile, valueFiles["atomix"][0])
+	bytes, err := ioutil.ReadFile(valueFiles["atomix"][1])
+	assert.NoError(t, err)
+	values := make(map[string]interface{})
+	assert.NoError(t, yaml.Unmarshal(bytes, &values))
+	assert.Equal(t, "raft", values["backend"])
+}
@@ -18,6 +18,6 @@ import "github.com/onosproject/helmit/pkg/registry"
 
 // Register registers a test suite
 // Deprecated: Use registry.RegisterTestSuite instead
-func Register(name string, suite TestingSuite) {
-	registry.RegisterTestSuite(name, suite)
+func Register(name string, suite TestingSuite, tags ...string) {
+	registry.RegisterTestSuite(name, suite, tags...)
 }
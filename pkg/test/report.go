@@ -0,0 +1,360 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportFormat is the format of a test report
+type ReportFormat string
+
+const (
+	// JUnitReport is a JUnit XML report
+	JUnitReport ReportFormat = "junit"
+	// JSONReport is a stream of JSON test events in the format written by go test -json
+	JSONReport ReportFormat = "json"
+)
+
+// Report is a report of the results of a test run to be written by the client
+type Report struct {
+	// Format is the format of the report
+	Format ReportFormat
+	// Path is the local path to which to write the report
+	Path string
+}
+
+// ParseReport parses a report in the format {format}={path}
+func ParseReport(report string) (Report, error) {
+	index := strings.Index(report, "=")
+	if index == -1 {
+		return Report{}, fmt.Errorf("report %s must be in the format {format}={path}", report)
+	}
+	format, path := ReportFormat(report[:index]), report[index+1:]
+	if format != JUnitReport && format != JSONReport {
+		return Report{}, fmt.Errorf("unknown report format %s", format)
+	}
+	if path == "" {
+		return Report{}, fmt.Errorf("report %s must specify a path", report)
+	}
+	return Report{
+		Format: format,
+		Path:   path,
+	}, nil
+}
+
+// writeReport writes the given result to the report's path
+func writeReport(result *Result, report Report) error {
+	if err := os.MkdirAll(filepath.Dir(report.Path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(report.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch report.Format {
+	case JUnitReport:
+		return writeJUnitReport(file, result)
+	case JSONReport:
+		return writeJSONReport(file, result)
+	}
+	return fmt.Errorf("unknown report format %s", report.Format)
+}
+
+// getSuiteNames returns the name of each suite in the result
+// Suites run with a matrix entry are labeled with the name of the entry. When suites were run for more than one
+// iteration, the iteration is appended to the name of the suite to distinguish each run of the suite.
+func getSuiteNames(result *Result) []string {
+	iterations := false
+	for _, suite := range result.Suites {
+		if suite.Iteration > 1 {
+			iterations = true
+		}
+	}
+	names := make([]string, len(result.Suites))
+	for i, suite := range result.Suites {
+		if iterations {
+			names[i] = fmt.Sprintf("%s#%d", suite.Label(), suite.Iteration)
+		} else {
+			names[i] = suite.Label()
+		}
+	}
+	return names
+}
+
+// joinOutput joins lines of output
+func joinOutput(output []string) string {
+	if len(output) == 0 {
+		return ""
+	}
+	return strings.Join(output, "\n") + "\n"
+}
+
+// seconds formats the given duration as a number of seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name         string        `xml:"name,attr"`
+	ClassName    string        `xml:"classname,attr"`
+	Time         string        `xml:"time,attr"`
+	Failure      *junitMessage `xml:"failure,omitempty"`
+	FlakyFailure *junitMessage `xml:"flakyFailure,omitempty"`
+	Skipped      *junitMessage `xml:"skipped,omitempty"`
+	SystemOut    string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// writeJUnitReport writes the result as JUnit XML with a testsuite for each run of a suite
+// Failures that prevented a suite from completing are reported as a failed test case named for the suite, and
+// suites skipped before they were run are reported as a skipped test case named for the suite. Flaky
+// tests are reported as passed test cases with a flakyFailure element, as is done by the Maven Surefire plugin.
+func writeJUnitReport(out io.Writer, result *Result) error {
+	report := junitTestSuites{}
+	var elapsed time.Duration
+	for i, name := range getSuiteNames(result) {
+		suite := result.Suites[i]
+		junitSuite := junitTestSuite{
+			Name: name,
+			Time: seconds(suite.Elapsed),
+			Properties: []junitProperty{
+				{
+					Name:  "iteration",
+					Value: strconv.Itoa(suite.Iteration),
+				},
+			},
+			SystemOut: joinOutput(suite.Output),
+		}
+		if suite.Matrix != "" {
+			junitSuite.Properties = append(junitSuite.Properties, junitProperty{
+				Name:  "matrix",
+				Value: suite.Matrix,
+			})
+		}
+		if result.Seed != nil {
+			junitSuite.Properties = append(junitSuite.Properties, junitProperty{
+				Name:  "seed",
+				Value: strconv.FormatInt(*result.Seed, 10),
+			})
+		}
+
+		failed := false
+		for _, test := range suite.Tests {
+			testCase := junitTestCase{
+				Name:      test.Name,
+				ClassName: name,
+				Time:      seconds(test.Elapsed),
+				SystemOut: joinOutput(test.Output),
+			}
+			switch test.Status {
+			case StatusFailed:
+				testCase.Failure = &junitMessage{
+					Message: getAttemptsMessage(test, "Failed"),
+					Content: joinOutput(test.Output),
+				}
+				junitSuite.Failures++
+				failed = true
+			case StatusFlaky:
+				testCase.FlakyFailure = &junitMessage{
+					Message: getAttemptsMessage(test, "Flaky"),
+					Content: joinOutput(test.Output),
+				}
+			case StatusSkipped:
+				testCase.Skipped = &junitMessage{
+					Message: "Skipped",
+				}
+				junitSuite.Skipped++
+			}
+			junitSuite.TestCases = append(junitSuite.TestCases, testCase)
+		}
+
+		if suite.Status == StatusSkipped && len(suite.Tests) == 0 {
+			junitSuite.TestCases = append(junitSuite.TestCases, junitTestCase{
+				Name:      suite.Suite,
+				ClassName: name,
+				Time:      seconds(suite.Elapsed),
+				Skipped: &junitMessage{
+					Message: suite.SkipReason,
+				},
+			})
+			junitSuite.Skipped++
+		}
+
+		if suite.Failed() && (!failed || suite.Error != "") {
+			message := suite.Error
+			if message == "" {
+				message = "Failed"
+			}
+			junitSuite.TestCases = append(junitSuite.TestCases, junitTestCase{
+				Name:      suite.Suite,
+				ClassName: name,
+				Time:      seconds(suite.Elapsed),
+				Failure: &junitMessage{
+					Message: message,
+					Content: joinOutput(suite.Output),
+				},
+			})
+			junitSuite.Failures++
+		}
+
+		junitSuite.Tests = len(junitSuite.TestCases)
+		report.Tests += junitSuite.Tests
+		report.Failures += junitSuite.Failures
+		report.Skipped += junitSuite.Skipped
+		report.Suites = append(report.Suites, junitSuite)
+		elapsed += suite.Elapsed
+	}
+	report.Time = seconds(elapsed)
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// jsonEvent is a test event in the format written by go test -json
+type jsonEvent struct {
+	Action  string   `json:"Action"`
+	Package string   `json:"Package,omitempty"`
+	Test    string   `json:"Test,omitempty"`
+	Elapsed *float64 `json:"Elapsed,omitempty"`
+	Output  string   `json:"Output,omitempty"`
+}
+
+// writeJSONReport writes the result as a stream of JSON test events in the format written by go test -json
+// Each run of a suite is reported as a package. If the tests were shuffled, the seed is reported as package output
+// in the same form as go test -shuffle.
+func writeJSONReport(out io.Writer, result *Result) error {
+	encoder := json.NewEncoder(out)
+	for i, name := range getSuiteNames(result) {
+		suite := result.Suites[i]
+		if result.Seed != nil {
+			if err := encoder.Encode(jsonEvent{Action: "output", Package: name, Output: fmt.Sprintf("-test.shuffle %d\n", *result.Seed)}); err != nil {
+				return err
+			}
+		}
+		for _, test := range suite.Tests {
+			if err := encoder.Encode(jsonEvent{Action: "run", Package: name, Test: test.Name}); err != nil {
+				return err
+			}
+			output := test.Output
+			if test.Passes+test.Failures > 1 {
+				output = append(append([]string{}, output...), getAttemptsMessage(test, string(test.Status)))
+			}
+			for _, line := range output {
+				if err := encoder.Encode(jsonEvent{Action: "output", Package: name, Test: test.Name, Output: line + "\n"}); err != nil {
+					return err
+				}
+			}
+			if action := getJSONAction(test.Status); action != "" {
+				elapsed := test.Elapsed.Seconds()
+				if err := encoder.Encode(jsonEvent{Action: action, Package: name, Test: test.Name, Elapsed: &elapsed}); err != nil {
+					return err
+				}
+			}
+		}
+
+		output := append([]string{}, suite.Output...)
+		if suite.Error != "" {
+			output = append(output, suite.Error)
+		}
+		if suite.SkipReason != "" {
+			output = append(output, suite.SkipReason)
+		}
+		for _, line := range output {
+			if err := encoder.Encode(jsonEvent{Action: "output", Package: name, Output: line + "\n"}); err != nil {
+				return err
+			}
+		}
+		action := getJSONAction(suite.Status)
+		if action == "" {
+			action = "fail"
+		}
+		elapsed := suite.Elapsed.Seconds()
+		if err := encoder.Encode(jsonEvent{Action: action, Package: name, Elapsed: &elapsed}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAttemptsMessage returns a message describing the number of attempts in which the test passed
+func getAttemptsMessage(test *TestResult, status string) string {
+	attempts := test.Passes + test.Failures
+	if attempts <= 1 {
+		return status
+	}
+	return fmt.Sprintf("%s: passed %d of %d attempts", status, test.Passes, attempts)
+}
+
+// getJSONAction returns the go test -json action for the given completed status
+// Flaky tests are reported as passed.
+func getJSONAction(status Status) string {
+	switch status {
+	case StatusPassed, StatusFlaky:
+		return "pass"
+	case StatusFailed:
+		return "fail"
+	case StatusSkipped:
+		return "skip"
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestResult() *Result {
+	return &Result{
+		Suites: []*SuiteResult{
+			{
+				Suite:     "suite",
+				Iteration: 1,
+				Status:    StatusFailed,
+				Elapsed:   time.Minute,
+				Tests: []*TestResult{
+					{Name: "TestFoo", Status: StatusPassed, Elapsed: time.Second, Output: []string{"foo"}},
+					{Name: "TestBar", Status: StatusFailed, Elapsed: time.Second, Output: []string{"bar"}},
+					{Name: "TestBaz", Status: StatusSkipped},
+					{Name: "TestQux", Status: StatusFlaky, Passes: 1, Failures: 1},
+				},
+			},
+			{
+				Suite:     "suite",
+				Iteration: 2,
+				Status:    StatusFailed,
+				Error:     "worker exited with status 2",
+			},
+		},
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	report, err := ParseReport("junit=report.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, JUnitReport, report.Format)
+	assert.Equal(t, "report.xml", report.Path)
+
+	_, err = ParseReport("junit")
+	assert.Error(t, err)
+	_, err = ParseReport("html=report.html")
+	assert.Error(t, err)
+}
+
+func TestJUnitReport(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, writeJUnitReport(buf, newTestResult()))
+
+	report := junitTestSuites{}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 2, report.Failures)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Len(t, report.Suites, 2)
+	assert.Equal(t, "suite#1", report.Suites[0].Name)
+	assert.Equal(t, "suite#2", report.Suites[1].Name)
+	assert.NotNil(t, report.Suites[0].TestCases[1].Failure)
+	assert.NotNil(t, report.Suites[0].TestCases[2].Skipped)
+	assert.Nil(t, report.Suites[0].TestCases[3].Failure)
+	assert.Equal(t, "Flaky: passed 1 of 2 attempts", report.Suites[0].TestCases[3].FlakyFailure.Message)
+	assert.Equal(t, "worker exited with status 2", report.Suites[1].TestCases[0].Failure.Message)
+
+	seed := int64(42)
+	result := newTestResult()
+	result.Seed = &seed
+	buf = &bytes.Buffer{}
+	assert.NoError(t, writeJUnitReport(buf, result))
+	report = junitTestSuites{}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+	assert.Contains(t, report.Suites[0].Properties, junitProperty{Name: "seed", Value: "42"})
+}
+
+func TestJSONReport(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, writeJSONReport(buf, newTestResult()))
+
+	var actions []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := jsonEvent{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		actions = append(actions, event.Package+"/"+event.Test+":"+event.Action)
+	}
+	assert.Equal(t, []string{
+		"suite#1/TestFoo:run",
+		"suite#1/TestFoo:output",
+		"suite#1/TestFoo:pass",
+		"suite#1/TestBar:run",
+		"suite#1/TestBar:output",
+		"suite#1/TestBar:fail",
+		"suite#1/TestBaz:run",
+		"suite#1/TestBaz:skip",
+		"suite#1/TestQux:run",
+		"suite#1/TestQux:output",
+		"suite#1/TestQux:pass",
+		"suite#1/:fail",
+		"suite#2/:output",
+		"suite#2/:fail",
+	}, actions)
+}
@@ -0,0 +1,142 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"fmt"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Requirements are the capabilities a cluster must have to run a suite
+type Requirements struct {
+	// MinVersion is the minimum Kubernetes server version, e.g. "1.16"
+	MinVersion string
+	// APIResources are the API resources the cluster must serve, e.g. the resources defined by required CRDs
+	APIResources []schema.GroupVersionResource
+	// MinNodes is the minimum number of nodes in the cluster
+	MinNodes int
+	// StorageClasses are the names of the storage classes the cluster must provide
+	StorageClasses []string
+}
+
+// RequirementsTestSuite is an interface for suites that require capabilities of the cluster
+// The requirements are checked by the worker before the suite is set up. If the cluster does not meet the
+// requirements, the suite is skipped with the reasons it could not be run.
+type RequirementsTestSuite interface {
+	Requirements() Requirements
+}
+
+// checkRequirements returns the reasons the cluster does not meet the given requirements
+// Nodes and storage classes are cluster-scoped, so workers whose roles are scoped to their namespace may be forbidden
+// from reading them. Requirements that cannot be verified are reported as unmet with the reason.
+func checkRequirements(client kubernetes.Interface, requirements Requirements) ([]string, error) {
+	var unmet []string
+	if requirements.MinVersion != "" {
+		minVersion, err := version.ParseGeneric(requirements.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		info, err := client.Discovery().ServerVersion()
+		if err != nil {
+			return nil, err
+		}
+		serverVersion, err := version.ParseGeneric(info.GitVersion)
+		if err != nil {
+			return nil, err
+		}
+		if !serverVersion.AtLeast(minVersion) {
+			unmet = append(unmet, fmt.Sprintf("server version %s is older than %s", info.GitVersion, requirements.MinVersion))
+		}
+	}
+
+	if len(requirements.APIResources) > 0 {
+		missing, err := getMissingAPIResources(client, requirements.APIResources)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range missing {
+			unmet = append(unmet, fmt.Sprintf("API resource %s in %s is not served", resource.Resource, resource.GroupVersion()))
+		}
+	}
+
+	if requirements.MinNodes > 0 {
+		nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+		if k8serrors.IsForbidden(err) {
+			unmet = append(unmet, fmt.Sprintf("cannot verify the cluster has %d nodes: %s", requirements.MinNodes, forbiddenReason("nodes")))
+		} else if err != nil {
+			return nil, err
+		} else if len(nodes.Items) < requirements.MinNodes {
+			unmet = append(unmet, fmt.Sprintf("cluster has %d nodes but %d are required", len(nodes.Items), requirements.MinNodes))
+		}
+	}
+
+	for _, name := range requirements.StorageClasses {
+		_, err := client.StorageV1().StorageClasses().Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			unmet = append(unmet, fmt.Sprintf("storage class %s does not exist", name))
+		} else if k8serrors.IsForbidden(err) {
+			unmet = append(unmet, fmt.Sprintf("cannot verify storage class %s exists: %s", name, forbiddenReason("storageclasses")))
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return unmet, nil
+}
+
+// forbiddenReason returns the reason a cluster-scoped resource could not be read by the worker
+func forbiddenReason(resource string) string {
+	return fmt.Sprintf("reading %s is forbidden; with --rbac-scope namespace, the cluster must grant read access to %s to job service accounts", resource, resource)
+}
+
+// getMissingAPIResources returns the given resources that are not served by the API server
+func getMissingAPIResources(client kubernetes.Interface, resources []schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	groups, err := client.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	served := make(map[string]bool)
+	for _, group := range groups.Groups {
+		for _, groupVersion := range group.Versions {
+			served[groupVersion.GroupVersion] = true
+		}
+	}
+
+	var missing []schema.GroupVersionResource
+	for _, resource := range resources {
+		groupVersion := resource.GroupVersion().String()
+		if !served[groupVersion] {
+			missing = append(missing, resource)
+			continue
+		}
+		list, err := client.Discovery().ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, apiResource := range list.APIResources {
+			if apiResource.Name == resource.Resource {
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, resource)
+		}
+	}
+	return missing, nil
+}
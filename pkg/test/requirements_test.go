@@ -0,0 +1,90 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"strings"
+	"testing"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}})
+	discovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.17.3"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments"}, {Name: "statefulsets"}},
+		},
+	}
+
+	unmet, err := checkRequirements(client, Requirements{
+		MinVersion:     "1.16",
+		APIResources:   []schema.GroupVersionResource{{Group: "apps", Version: "v1", Resource: "statefulsets"}},
+		MinNodes:       1,
+		StorageClasses: []string{"standard"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, unmet)
+
+	unmet, err = checkRequirements(client, Requirements{
+		MinVersion: "1.18",
+		APIResources: []schema.GroupVersionResource{
+			{Group: "apps", Version: "v1", Resource: "daemonsets"},
+			{Group: "cloud.atomix.io", Version: "v1beta1", Resource: "databases"},
+		},
+		MinNodes:       3,
+		StorageClasses: []string{"fast"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"server version v1.17.3 is older than 1.18",
+		"API resource daemonsets in apps/v1 is not served",
+		"API resource databases in cloud.atomix.io/v1beta1 is not served",
+		"cluster has 1 nodes but 3 are required",
+		"storage class fast does not exist",
+	}, unmet)
+}
+
+func TestCheckRequirementsForbidden(t *testing.T) {
+	// Workers with namespace scoped roles may not be able to read cluster-scoped resources
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		resource := action.GetResource().GroupResource()
+		return true, nil, k8serrors.NewForbidden(resource, "", nil)
+	})
+
+	unmet, err := checkRequirements(client, Requirements{
+		MinNodes:       3,
+		StorageClasses: []string{"fast"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, unmet, 2)
+	assert.True(t, strings.HasPrefix(unmet[0], "cannot verify the cluster has 3 nodes: reading nodes is forbidden"))
+	assert.True(t, strings.HasPrefix(unmet[1], "cannot verify storage class fast exists: reading storageclasses is forbidden"))
+}
@@ -0,0 +1,330 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/job"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// Status is the status of a test or suite
+type Status string
+
+const (
+	// StatusPending indicates a test has not started
+	StatusPending Status = "pending"
+	// StatusRunning indicates a test has started but not completed
+	StatusRunning Status = "running"
+	// StatusPassed indicates a test passed
+	StatusPassed Status = "passed"
+	// StatusFailed indicates a test failed
+	StatusFailed Status = "failed"
+	// StatusSkipped indicates a test was skipped
+	StatusSkipped Status = "skipped"
+	// StatusFlaky indicates a test failed but passed when retried
+	StatusFlaky Status = "flaky"
+)
+
+// resultsFile is the name of the file in the coordinator's artifacts to which the result of a run is written
+const resultsFile = "results.json"
+
+// Result is the aggregate result of a test run
+type Result struct {
+	// Suites is the list of results for each suite run, in the order in which they were run
+	Suites []*SuiteResult `json:"suites"`
+	// Seed is the seed with which the order of suites and tests was randomized, if they were shuffled
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// Failed returns whether any suite in the run failed
+func (r *Result) Failed() bool {
+	for _, suite := range r.Suites {
+		if suite.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes a summary of the result to the given writer
+func (r *Result) Print(out io.Writer) {
+	var passed, failed, flaky, skipped, skippedSuites int
+	writer := new(tabwriter.Writer)
+	writer.Init(out, 0, 0, 3, ' ', tabwriter.FilterHTML)
+	fmt.Fprintln(writer, "SUITE\tTEST\tRESULT\tPASSES\tFAILURES\tTIME")
+	for _, suite := range r.Suites {
+		for _, test := range suite.Tests {
+			switch test.Status {
+			case StatusPassed:
+				passed++
+			case StatusFailed:
+				failed++
+			case StatusFlaky:
+				flaky++
+			case StatusSkipped:
+				skipped++
+			}
+			fmt.Fprintln(writer, fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%s",
+				suite.Label(), test.Name, test.Status, test.Passes, test.Failures, test.Elapsed))
+		}
+		if suite.Error != "" {
+			fmt.Fprintln(writer, fmt.Sprintf("%s\t\t%s\t\t\t%s", suite.Label(), StatusFailed, suite.Error))
+		} else if suite.Failed() && len(suite.Tests) == 0 {
+			fmt.Fprintln(writer, fmt.Sprintf("%s\t\t%s\t\t\t%s", suite.Label(), StatusFailed, suite.Elapsed))
+		} else if suite.Status == StatusSkipped {
+			skippedSuites++
+			fmt.Fprintln(writer, fmt.Sprintf("%s\t\t%s\t\t\t%s", suite.Label(), StatusSkipped, suite.SkipReason))
+		}
+	}
+	writer.Flush()
+	fmt.Fprintf(out, "%d passed, %d failed, %d flaky, %d skipped", passed, failed, flaky, skipped)
+	if skippedSuites > 0 {
+		fmt.Fprintf(out, ", %d suites skipped", skippedSuites)
+	}
+	fmt.Fprintln(out)
+	if r.Seed != nil {
+		fmt.Fprintf(out, "Shuffled with seed %d\n", *r.Seed)
+	}
+}
+
+// SuiteResult is the result of running a suite of tests
+type SuiteResult struct {
+	// Suite is the name of the suite
+	Suite string `json:"suite"`
+	// Matrix is the name of the matrix entry with which the suite was run, if any
+	Matrix string `json:"matrix,omitempty"`
+	// Iteration is the iteration of the run in which the suite was run
+	Iteration int `json:"iteration"`
+	// Status is the status of the suite
+	Status Status `json:"status"`
+	// Elapsed is the time taken to run the suite
+	Elapsed time.Duration `json:"elapsed"`
+	// Error is the error that prevented the suite from completing, if any
+	Error string `json:"error,omitempty"`
+	// SkipReason is the reason the suite was skipped, if it was skipped
+	SkipReason string `json:"skipReason,omitempty"`
+	// Tests is the list of results for each test in the suite, in the order in which they were started
+	Tests []*TestResult `json:"tests"`
+	// Output is the output written by the suite outside of any test
+	Output []string `json:"output,omitempty"`
+	// Attempts is the number of times the suite was run, including retries of its failed tests
+	Attempts int `json:"attempts"`
+	tests    map[string]*TestResult
+}
+
+// newSuiteResult returns a new pending result for the given suite
+func newSuiteResult(suite string, iteration int) *SuiteResult {
+	return &SuiteResult{
+		Suite:     suite,
+		Iteration: iteration,
+		Status:    StatusPending,
+		Attempts:  1,
+		tests:     make(map[string]*TestResult),
+	}
+}
+
+// Label returns the label identifying the suite and the matrix entry with which it was run
+func (r *SuiteResult) Label() string {
+	return getSuiteLabel(r.Suite, r.Matrix)
+}
+
+// Failed returns whether the suite failed
+func (r *SuiteResult) Failed() bool {
+	return r.Status == StatusFailed
+}
+
+// getTest returns the result for the given test, adding it if necessary
+func (r *SuiteResult) getTest(name string) *TestResult {
+	if r.tests == nil {
+		r.tests = make(map[string]*TestResult)
+		for _, test := range r.Tests {
+			r.tests[test.Name] = test
+		}
+	}
+	test, ok := r.tests[name]
+	if !ok {
+		test = &TestResult{
+			Name:   name,
+			Status: StatusPending,
+		}
+		r.tests[name] = test
+		r.Tests = append(r.Tests, test)
+	}
+	return test
+}
+
+// handleEvent updates the result with the given event from the suite's worker
+func (r *SuiteResult) handleEvent(event *TestEvent) {
+	if event.Test == "" {
+		switch event.Type {
+		case TestEventType_START:
+			r.Status = StatusRunning
+		case TestEventType_OUTPUT:
+			r.Output = append(r.Output, event.Output)
+		case TestEventType_SKIP:
+			r.Status = StatusSkipped
+			r.Elapsed = event.Elapsed
+			r.SkipReason = event.Output
+		default:
+			r.Status = getEventStatus(event.Type)
+			r.Elapsed = event.Elapsed
+		}
+		return
+	}
+
+	test := r.getTest(event.Test)
+	switch event.Type {
+	case TestEventType_START:
+		test.Status = StatusRunning
+	case TestEventType_OUTPUT:
+		test.Output = append(test.Output, event.Output)
+	case TestEventType_PASS:
+		test.Status = StatusPassed
+		test.Elapsed = event.Elapsed
+		test.Passes++
+	case TestEventType_FAIL:
+		test.Status = StatusFailed
+		test.Elapsed = event.Elapsed
+		test.Failures++
+	case TestEventType_SKIP:
+		test.Status = StatusSkipped
+		test.Elapsed = event.Elapsed
+	}
+}
+
+// failedTests returns the names of the suite's failed tests
+func (r *SuiteResult) failedTests() []string {
+	var names []string
+	for _, test := range r.Tests {
+		if test.Status == StatusFailed {
+			names = append(names, test.Name)
+		}
+	}
+	return names
+}
+
+// addRetry merges the result of a retry of the suite's failed tests into the result
+// Tests that failed and then passed are marked flaky, and the suite passes once all its failed tests have passed.
+func (r *SuiteResult) addRetry(retry *SuiteResult) {
+	r.Attempts++
+	r.Elapsed += retry.Elapsed
+	r.Output = append(r.Output, retry.Output...)
+	for _, test := range retry.Tests {
+		original := r.getTest(test.Name)
+		original.Passes += test.Passes
+		original.Failures += test.Failures
+		original.Elapsed += test.Elapsed
+		original.Output = append(original.Output, test.Output...)
+		if test.Status == StatusPassed && original.Failures > 0 {
+			original.Status = StatusFlaky
+		} else {
+			original.Status = test.Status
+		}
+	}
+	r.Status = retry.Status
+	r.Error = retry.Error
+}
+
+// fail marks the suite failed with the given error
+// Tests that were started but not completed are marked failed.
+func (r *SuiteResult) fail(err error) {
+	r.Status = StatusFailed
+	r.Error = err.Error()
+	for _, test := range r.Tests {
+		if test.Status == StatusRunning {
+			test.Status = StatusFailed
+			test.Failures++
+		}
+	}
+}
+
+// getEventStatus returns the status for the given completion event type
+func getEventStatus(eventType TestEventType) Status {
+	switch eventType {
+	case TestEventType_PASS:
+		return StatusPassed
+	case TestEventType_SKIP:
+		return StatusSkipped
+	}
+	return StatusFailed
+}
+
+// TestResult is the result of a single test
+type TestResult struct {
+	// Name is the name of the test
+	Name string `json:"name"`
+	// Status is the status of the test
+	Status Status `json:"status"`
+	// Elapsed is the time taken to run the test
+	Elapsed time.Duration `json:"elapsed"`
+	// Output is the output written by the test
+	Output []string `json:"output,omitempty"`
+	// Passes is the number of attempts in which the test passed
+	Passes int `json:"passes"`
+	// Failures is the number of attempts in which the test failed
+	Failures int `json:"failures"`
+}
+
+// writeResult writes the result to the coordinator's artifacts to be copied back to the client
+// The result is only written if the coordinator is collecting artifacts.
+func writeResult(result *Result) error {
+	if _, err := os.Stat(job.ArtifactsPath); err != nil {
+		return nil
+	}
+	bytes, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(job.ArtifactsPath, resultsFile), bytes, 0644)
+}
+
+// readResult reads the result of a run from the given file
+func readResult(file string) (*Result, error) {
+	bytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	if err := json.Unmarshal(bytes, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LoadDurations loads the mean duration of each suite from the results of a previous run
+func LoadDurations(file string) (map[string]time.Duration, error) {
+	result, err := readResult(file)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for _, suite := range result.Suites {
+		totals[suite.Label()] += suite.Elapsed
+		counts[suite.Label()]++
+	}
+	durations := make(map[string]time.Duration)
+	for suite, total := range totals {
+		durations[suite] = total / time.Duration(counts[suite])
+	}
+	return durations, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSuiteResult(t *testing.T) {
+	result := newSuiteResult("suite", 1)
+	result.handleEvent(&TestEvent{Type: TestEventType_START})
+	result.handleEvent(&TestEvent{Type: TestEventType_START, Test: "TestFoo"})
+	result.handleEvent(&TestEvent{Type: TestEventType_OUTPUT, Test: "TestFoo", Output: "foo"})
+	result.handleEvent(&TestEvent{Type: TestEventType_PASS, Test: "TestFoo", Elapsed: time.Second})
+	result.handleEvent(&TestEvent{Type: TestEventType_START, Test: "TestBar"})
+	result.handleEvent(&TestEvent{Type: TestEventType_SKIP, Test: "TestBar"})
+	assert.Equal(t, StatusRunning, result.Status)
+	assert.Len(t, result.Tests, 2)
+	assert.Equal(t, StatusPassed, result.Tests[0].Status)
+	assert.Equal(t, time.Second, result.Tests[0].Elapsed)
+	assert.Equal(t, []string{"foo"}, result.Tests[0].Output)
+	assert.Equal(t, StatusSkipped, result.Tests[1].Status)
+
+	result.handleEvent(&TestEvent{Type: TestEventType_PASS, Elapsed: time.Minute})
+	assert.False(t, result.Failed())
+	assert.Equal(t, time.Minute, result.Elapsed)
+
+	result = newSuiteResult("suite", 1)
+	result.handleEvent(&TestEvent{Type: TestEventType_START})
+	result.handleEvent(&TestEvent{Type: TestEventType_START, Test: "TestFoo"})
+	result.fail(errors.New("worker crashed"))
+	assert.True(t, result.Failed())
+	assert.Equal(t, "worker crashed", result.Error)
+	assert.Equal(t, StatusFailed, result.Tests[0].Status)
+	assert.True(t, (&Result{Suites: []*SuiteResult{result}}).Failed())
+}
+
+func TestParseTestName(t *testing.T) {
+	name, ok := parseTestName("=== RUN   suite/TestFoo")
+	assert.True(t, ok)
+	assert.Equal(t, "TestFoo", name)
+
+	name, ok = parseTestName("    --- FAIL: suite/TestBar/sub (0.00s)")
+	assert.True(t, ok)
+	assert.Equal(t, "TestBar", name)
+
+	name, ok = parseTestName("--- PASS: suite (1.00s)")
+	assert.True(t, ok)
+	assert.Equal(t, "", name)
+
+	_, ok = parseTestName("    test.go:10: some output")
+	assert.False(t, ok)
+}
+
+func TestSuiteResultRetry(t *testing.T) {
+	result := newSuiteResult("suite", 1)
+	result.handleEvent(&TestEvent{Type: TestEventType_START})
+	result.handleEvent(&TestEvent{Type: TestEventType_PASS, Test: "TestFoo"})
+	result.handleEvent(&TestEvent{Type: TestEventType_FAIL, Test: "TestBar"})
+	result.handleEvent(&TestEvent{Type: TestEventType_FAIL, Test: "TestBaz"})
+	result.handleEvent(&TestEvent{Type: TestEventType_FAIL})
+	assert.Equal(t, []string{"TestBar", "TestBaz"}, result.failedTests())
+
+	retry := newSuiteResult("suite", 1)
+	retry.handleEvent(&TestEvent{Type: TestEventType_START})
+	retry.handleEvent(&TestEvent{Type: TestEventType_PASS, Test: "TestBar"})
+	retry.handleEvent(&TestEvent{Type: TestEventType_FAIL, Test: "TestBaz"})
+	retry.handleEvent(&TestEvent{Type: TestEventType_FAIL})
+	result.addRetry(retry)
+	assert.True(t, result.Failed())
+	assert.Equal(t, 2, result.Attempts)
+	assert.Equal(t, StatusPassed, result.Tests[0].Status)
+	assert.Equal(t, StatusFlaky, result.Tests[1].Status)
+	assert.Equal(t, 1, result.Tests[1].Passes)
+	assert.Equal(t, 1, result.Tests[1].Failures)
+	assert.Equal(t, StatusFailed, result.Tests[2].Status)
+	assert.Equal(t, 2, result.Tests[2].Failures)
+	assert.Equal(t, []string{"TestBaz"}, result.failedTests())
+
+	retry = newSuiteResult("suite", 1)
+	retry.handleEvent(&TestEvent{Type: TestEventType_START})
+	retry.handleEvent(&TestEvent{Type: TestEventType_PASS, Test: "TestBaz"})
+	retry.handleEvent(&TestEvent{Type: TestEventType_PASS})
+	result.addRetry(retry)
+	assert.False(t, result.Failed())
+	assert.Equal(t, StatusFlaky, result.Tests[2].Status)
+	assert.Equal(t, 1, result.Tests[2].Passes)
+	assert.Equal(t, 2, result.Tests[2].Failures)
+}
@@ -15,9 +15,11 @@
 package test
 
 import (
+	"fmt"
 	jobs "github.com/onosproject/helmit/pkg/job"
 	"os"
 	"path"
+	"path/filepath"
 )
 
 // The executor is the entrypoint for test images. It takes the input and environment and runs
@@ -46,6 +48,14 @@ func Run(config *Config) error {
 		configContext = path.Base(config.Context)
 	}
 
+	// The coordinator collects artifacts into its own artifacts directory to be copied back to the client
+	// If no artifacts directory is configured, artifacts are copied to a default local directory so that
+	// diagnostics for failed jobs are always available.
+	if config.ArtifactsDir == "" {
+		config.ArtifactsDir = jobs.DefaultArtifactsDir()
+	}
+	configArtifactsDir := jobs.ArtifactsPath
+
 	job := &jobs.Job{
 		Config: config.Config,
 		JobConfig: &Config{
@@ -57,23 +67,77 @@ func Run(config *Config) error {
 				Context:         configContext,
 				Values:          config.Values,
 				ValueFiles:      configValueFiles,
+				StrictValues:    config.StrictValues,
+				HelmDriver:      config.HelmDriver,
 				Args:            config.Args,
 				Env:             config.Env,
 				Timeout:         config.Timeout,
+				PodTemplate:     config.PodTemplate,
+				NoTeardown:      config.NoTeardown,
+				KeepOnFailure:   config.KeepOnFailure,
+				ArtifactsDir:    configArtifactsDir,
+				RBAC:            config.RBAC,
 			},
-			Suites:     config.Suites,
-			Tests:      config.Tests,
-			Iterations: config.Iterations,
-			Verbose:    config.Verbose,
+			Suites:             config.Suites,
+			Tests:              config.Tests,
+			Tags:               config.Tags,
+			SkipTags:           config.SkipTags,
+			Iterations:         config.Iterations,
+			Workers:            config.Workers,
+			Durations:          config.Durations,
+			Matrix:             config.Matrix,
+			SuiteTimeout:       config.SuiteTimeout,
+			TestTimeout:        config.TestTimeout,
+			Shuffle:            config.Shuffle,
+			Seed:               config.Seed,
+			Retries:            config.Retries,
+			RetrySameNamespace: config.RetrySameNamespace,
+			Verbose:            config.Verbose,
 		},
 		Type: testJobType,
 	}
-	return jobs.Run(job)
+	if len(config.Reports) == 0 {
+		return jobs.Run(job)
+	}
+
+	status, err := jobs.RunAndWait(job)
+	if err == nil {
+		if err := writeReports(config, filepath.Join(config.ArtifactsDir, config.ID, resultsFile)); err != nil {
+			fmt.Println(err)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		os.Exit(status)
+	}
+	return nil
+}
+
+// writeReports writes the configured reports from the results in the given file
+func writeReports(config *Config, file string) error {
+	result, err := readResult(file)
+	if err != nil {
+		return fmt.Errorf("failed to read test results: %v", err)
+	}
+	for _, report := range config.Reports {
+		if err := writeReport(result, report); err != nil {
+			return fmt.Errorf("failed to write %s report %s: %v", report.Format, report.Path, err)
+		}
+	}
+	return nil
 }
 
 // Main runs a test
 func Main() {
-	if err := run(); err != nil {
+	if err := run(); err == jobs.ErrCanceled {
+		println("Test run canceled")
+		os.Exit(jobs.CanceledExitCode)
+	} else if err != nil {
 		println("Test run failed " + err.Error())
 		os.Exit(1)
 	}
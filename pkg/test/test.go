@@ -16,11 +16,18 @@ package test
 
 import (
 	"fmt"
+	"github.com/onosproject/helmit/pkg/job"
+	"github.com/onosproject/helmit/pkg/registry"
+	"math/rand"
 	"os"
 	"reflect"
 	"regexp"
+	"runtime"
 	"runtime/debug"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestingSuite is a suite of tests
@@ -59,6 +66,26 @@ type AfterTest interface {
 	AfterTest(testName string) error
 }
 
+// ParallelTestSuite is an interface for suites whose test methods can be run in parallel
+// Parallelism returns the maximum number of test methods to run concurrently. Suite setup and tear down hooks
+// run once around all the suite's tests, while SetupTest, BeforeTest, AfterTest and TearDownTest hooks run around
+// each test in the test's goroutine, so they may be called concurrently.
+type ParallelTestSuite interface {
+	Parallelism() int
+}
+
+// TimeoutTestSuite is an interface for suites that override the time allowed for all the suite's tests to run
+// A zero timeout uses the timeout given to the test command.
+type TimeoutTestSuite interface {
+	SuiteTimeout() time.Duration
+}
+
+// TimeoutTest is an interface for suites that override the time allowed for individual tests to run
+// A zero timeout uses the timeout given to the test command.
+type TimeoutTest interface {
+	TimeoutForTest(testName string) time.Duration
+}
+
 func failTestOnPanic(t *testing.T) {
 	r := recover()
 	if r != nil {
@@ -67,10 +94,70 @@ func failTestOnPanic(t *testing.T) {
 	}
 }
 
+// cancelHooks is the set of suite tear down functions to run if the worker is canceled
+var cancelHooks = make(map[*sync.Once]func())
+var cancelHooksMu sync.Mutex
+
+// runCancelHooks runs the tear down functions of suites that have been set up but not torn down
+func runCancelHooks() {
+	cancelHooksMu.Lock()
+	defer cancelHooksMu.Unlock()
+	for once, hook := range cancelHooks {
+		once.Do(hook)
+	}
+}
+
+// tearDownSuiteOnce returns a function that tears down the suite once, either when the tests complete or
+// when the worker is canceled
+func tearDownSuiteOnce(suite TestingSuite) func() {
+	once := &sync.Once{}
+	cancelHooksMu.Lock()
+	cancelHooks[once] = func() {
+		if tearDownTestSuite, ok := suite.(TearDownTestSuite); ok {
+			if err := tearDownTestSuite.TearDownTestSuite(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	cancelHooksMu.Unlock()
+	return func() {
+		cancelHooksMu.Lock()
+		delete(cancelHooks, once)
+		cancelHooksMu.Unlock()
+		once.Do(func() {
+			if tearDownTestSuite, ok := suite.(TearDownTestSuite); ok {
+				if err := tearDownTestSuite.TearDownTestSuite(); err != nil {
+					panic(err)
+				}
+			}
+		})
+	}
+}
+
 // RunTests runs a test suite
 func RunTests(t *testing.T, suite TestingSuite, cases []string) {
+	runTestSuite(t, suite, &TestRequest{Tests: cases})
+}
+
+// runTestSuite runs a test suite as configured by the request
+// Tests that exceed the request's suite or test timeouts fail with a dump of all goroutines and their tear down hooks
+// are run. The suite's setup and tear down are bounded by the suite timeout, though the tear down is given a grace
+// period if the timeout has already been exceeded. The expired test method cannot be stopped and is left running in the background. If the request enables
+// shuffling, the tests are run in a random order determined by the request's seed.
+func runTestSuite(t *testing.T, suite TestingSuite, request *TestRequest) {
 	defer failTestOnPanic(t)
 
+	cases := request.Tests
+	suiteTimeout := request.SuiteTimeout
+	testTimeout := request.TestTimeout
+	if timeoutTestSuite, ok := suite.(TimeoutTestSuite); ok && timeoutTestSuite.SuiteTimeout() > 0 {
+		suiteTimeout = timeoutTestSuite.SuiteTimeout()
+	}
+	var deadline time.Time
+	if suiteTimeout > 0 {
+		deadline = time.Now().Add(suiteTimeout)
+	}
+
 	suiteSetupDone := false
 
 	methodFinder := reflect.TypeOf(suite)
@@ -79,32 +166,61 @@ func RunTests(t *testing.T, suite TestingSuite, cases []string) {
 		method := methodFinder.Method(index)
 		ok, err := testFilter(method.Name, cases)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid regexp for -m: %s\n", err)
+			fmt.Fprintf(os.Stderr, "invalid test pattern: %s\n", err)
 			os.Exit(1)
 		}
 		if !ok {
 			continue
 		}
 		if !suiteSetupDone {
-			if setupTestSuite, ok := suite.(SetupTestSuite); ok {
-				if err := setupTestSuite.SetupTestSuite(); err != nil {
-					panic(err)
-				}
-			}
+			// The suite is torn down even if its setup times out, after the state of its releases is captured
+			tearDown := tearDownSuiteOnce(suite)
 			defer func() {
-				if tearDownTestSuite, ok := suite.(TearDownTestSuite); ok {
-					if err := tearDownTestSuite.TearDownTestSuite(); err != nil {
-						panic(err)
-					}
+				message := fmt.Sprintf("suite tear down timed out: suite timeout of %s exceeded", suiteTimeout)
+				runWithTimeout(t, getHookTimeout(deadline, suiteTearDownGracePeriod), message, tearDown)
+			}()
+			defer func() {
+				if t.Failed() {
+					job.CaptureReleases()
 				}
 			}()
+			if setupTestSuite, ok := suite.(SetupTestSuite); ok {
+				message := fmt.Sprintf("suite setup timed out: suite timeout of %s exceeded", suiteTimeout)
+				runWithTimeout(t, getHookTimeout(deadline, 0), message, func() {
+					if err := setupTestSuite.SetupTestSuite(); err != nil {
+						panic(err)
+					}
+				})
+			}
 			suiteSetupDone = true
 		}
 		test := testing.InternalTest{
 			Name: method.Name,
 			F: func(t *testing.T) {
+				// Report the result after any panic has been recovered and the test marked failed
+				start := time.Now()
+				reportEvent(&TestEvent{
+					Type: TestEventType_START,
+					Test: method.Name,
+				})
+				defer func() {
+					reportResult(t, method.Name, time.Since(start))
+				}()
 				defer failTestOnPanic(t)
 
+				timeout := testTimeout
+				if timeoutTest, ok := suite.(TimeoutTest); ok && timeoutTest.TimeoutForTest(method.Name) > 0 {
+					timeout = timeoutTest.TimeoutForTest(method.Name)
+				}
+				timeoutMessage := fmt.Sprintf("test timed out after %s", timeout)
+				if !deadline.IsZero() && (timeout == 0 || time.Until(deadline) < timeout) {
+					timeout = time.Until(deadline)
+					timeoutMessage = fmt.Sprintf("test timed out: suite timeout of %s exceeded", suiteTimeout)
+					if timeout <= 0 {
+						t.Fatalf("suite timed out after %s", suiteTimeout)
+					}
+				}
+
 				if setupTestSuite, ok := suite.(SetupTest); ok {
 					if err := setupTestSuite.SetupTest(); err != nil {
 						panic(err)
@@ -127,35 +243,178 @@ func RunTests(t *testing.T, suite TestingSuite, cases []string) {
 						}
 					}
 				}()
-				method.Func.Call([]reflect.Value{reflect.ValueOf(suite), reflect.ValueOf(t)})
+				runWithTimeout(t, timeout, timeoutMessage, func() {
+					method.Func.Call([]reflect.Value{reflect.ValueOf(suite), reflect.ValueOf(t)})
+				})
 			},
 		}
 		tests = append(tests, test)
 	}
-	runTests(t, tests)
+	if request.Shuffle {
+		fmt.Printf("-test.shuffle %d\n", request.Seed)
+		shuffleTests(tests, request.Seed)
+	}
+
+	parallelism := 1
+	if parallelTestSuite, ok := suite.(ParallelTestSuite); ok {
+		parallelism = parallelTestSuite.Parallelism()
+	}
+	runTests(t, tests, parallelism)
 }
 
-// runTest runs a test
-func runTests(t *testing.T, tests []testing.InternalTest) {
+// suiteTearDownGracePeriod is the time given to tear down a suite once the suite timeout has been exceeded
+const suiteTearDownGracePeriod = time.Minute
+
+// getHookTimeout returns the time remaining until the suite deadline for running a suite hook
+// If the deadline has passed, the hook is given the grace period. If no deadline is set, no timeout is returned.
+func getHookTimeout(deadline time.Time, gracePeriod time.Duration) time.Duration {
+	if deadline.IsZero() {
+		return 0
+	}
+	timeout := time.Until(deadline)
+	if timeout > 0 {
+		return timeout
+	} else if gracePeriod > 0 {
+		return gracePeriod
+	}
+	return time.Nanosecond
+}
+
+// shuffleTests randomizes the order of the tests using the given seed
+func shuffleTests(tests []testing.InternalTest, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(tests), func(i, j int) {
+		tests[i], tests[j] = tests[j], tests[i]
+	})
+}
+
+// runTests runs the tests, running up to parallelism tests concurrently
+// All the tests are complete when runTests returns.
+func runTests(t *testing.T, tests []testing.InternalTest, parallelism int) {
+	if parallelism <= 1 {
+		for _, test := range tests {
+			t.Run(test.Name, test.F)
+		}
+		return
+	}
+
+	sem := make(chan struct{}, parallelism)
+	wg := &sync.WaitGroup{}
 	for _, test := range tests {
-		t.Run(test.Name, test.F)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(test testing.InternalTest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			t.Run(test.Name, test.F)
+		}(test)
+	}
+	wg.Wait()
+}
+
+// runWithTimeout runs the test function, failing the test with a dump of all goroutines if it does not complete
+// within the timeout
+// The function is run in its own goroutine when a timeout is set, so the test can fail and its deferred tear down
+// hooks can run while the function is blocked.
+func runWithTimeout(t *testing.T, timeout time.Duration, message string, f func()) {
+	if timeout <= 0 {
+		f()
+		return
+	}
+
+	doneCh := make(chan string, 1)
+	go func() {
+		// Close the channel even if the test fails or skips from the function's goroutine
+		defer close(doneCh)
+		defer func() {
+			if r := recover(); r != nil {
+				doneCh <- fmt.Sprintf("test panicked: %v\n%s", r, debug.Stack())
+			}
+		}()
+		f()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case failure, ok := <-doneCh:
+		if ok {
+			t.Error(failure)
+			t.FailNow()
+		}
+	case <-timer.C:
+		t.Errorf("%s\n\n%s", message, dumpGoroutines())
+		t.FailNow()
+	}
+}
+
+// dumpGoroutines returns the stack traces of all goroutines
+func dumpGoroutines() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
 	}
 }
 
 // testFilter filters test method names
+// A method is selected if it matches any of the given regular expressions.
 func testFilter(name string, cases []string) (bool, error) {
 	if ok, _ := regexp.MatchString("^Test", name); !ok {
 		return false, nil
 	}
+	return matchAny(name, cases)
+}
 
-	if len(cases) == 0 || cases[0] == "" {
-		return true, nil
+// filterSuites returns the sorted names of the given suites that are selected by the patterns and tags
+// A suite is selected if it matches any of the patterns, has any of the tags, and has none of the skipped tags.
+// Empty patterns or tags select all suites.
+func filterSuites(suites []string, patterns []string, tags []string, skipTags []string) ([]string, error) {
+	selected := make([]string, 0, len(suites))
+	for _, suite := range suites {
+		ok, err := matchAny(suite, patterns)
+		if err != nil {
+			return nil, err
+		}
+		suiteTags := registry.GetTestSuiteTags(suite)
+		if ok && (len(tags) == 0 || hasAnyTag(suiteTags, tags)) && !hasAnyTag(suiteTags, skipTags) {
+			selected = append(selected, suite)
+		}
 	}
+	sort.Strings(selected)
+	return selected, nil
+}
 
-	for _, test := range cases {
-		if test == name {
+// matchAny returns whether the name matches any of the given regular expressions
+// An empty list of patterns matches all names.
+func matchAny(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 || (len(patterns) == 1 && patterns[0] == "") {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		ok, err := regexp.MatchString(pattern, name)
+		if err != nil {
+			return false, err
+		} else if ok {
 			return true, nil
 		}
 	}
 	return false, nil
 }
+
+// hasAnyTag returns whether any of the given tags is in the list of tags
+func hasAnyTag(tags []string, match []string) bool {
+	for _, tag := range tags {
+		for _, m := range match {
+			if tag == m {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -8,18 +8,22 @@ import (
 	fmt "fmt"
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
+	_ "github.com/gogo/protobuf/types"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
 	io "io"
 	math "math"
 	math_bits "math/bits"
+	time "time"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
+var _ = time.Kitchen
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
@@ -27,12 +31,60 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// TestEventType is the type of a test event
+type TestEventType int32
+
+const (
+	// START indicates a test has started
+	TestEventType_START TestEventType = 0
+	// PASS indicates a test has passed
+	TestEventType_PASS TestEventType = 1
+	// FAIL indicates a test has failed
+	TestEventType_FAIL TestEventType = 2
+	// SKIP indicates a test was skipped
+	TestEventType_SKIP TestEventType = 3
+	// OUTPUT is a line of output written while running a test
+	TestEventType_OUTPUT TestEventType = 4
+)
+
+var TestEventType_name = map[int32]string{
+	0: "START",
+	1: "PASS",
+	2: "FAIL",
+	3: "SKIP",
+	4: "OUTPUT",
+}
+
+var TestEventType_value = map[string]int32{
+	"START":  0,
+	"PASS":   1,
+	"FAIL":   2,
+	"SKIP":   3,
+	"OUTPUT": 4,
+}
+
+func (x TestEventType) String() string {
+	return proto.EnumName(TestEventType_name, int32(x))
+}
+
+func (TestEventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_84eb23d74a64bdab, []int{0}
+}
+
 // TestRequest is a test request
 type TestRequest struct {
 	// suite is the test suite to run
 	Suite string `protobuf:"bytes,1,opt,name=suite,proto3" json:"suite,omitempty"`
 	// tests are the tests to run
 	Tests []string `protobuf:"bytes,2,rep,name=tests,proto3" json:"tests,omitempty"`
+	// suite_timeout is the time allowed for all the suite's tests to run, or zero for no timeout
+	SuiteTimeout time.Duration `protobuf:"bytes,3,opt,name=suite_timeout,json=suiteTimeout,proto3,stdduration" json:"suite_timeout"`
+	// test_timeout is the time allowed for each test to run, or zero for no timeout
+	TestTimeout time.Duration `protobuf:"bytes,4,opt,name=test_timeout,json=testTimeout,proto3,stdduration" json:"test_timeout"`
+	// shuffle indicates whether to run the tests in a random order
+	Shuffle bool `protobuf:"varint,5,opt,name=shuffle,proto3" json:"shuffle,omitempty"`
+	// seed is the seed with which to randomize the order of the tests
+	Seed int64 `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`
 }
 
 func (m *TestRequest) Reset()         { *m = TestRequest{} }
@@ -82,22 +134,58 @@ func (m *TestRequest) GetTests() []string {
 	return nil
 }
 
-// TestResponse is a test response
-type TestResponse struct {
+func (m *TestRequest) GetSuiteTimeout() time.Duration {
+	if m != nil {
+		return m.SuiteTimeout
+	}
+	return 0
+}
+
+func (m *TestRequest) GetTestTimeout() time.Duration {
+	if m != nil {
+		return m.TestTimeout
+	}
+	return 0
 }
 
-func (m *TestResponse) Reset()         { *m = TestResponse{} }
-func (m *TestResponse) String() string { return proto.CompactTextString(m) }
-func (*TestResponse) ProtoMessage()    {}
-func (*TestResponse) Descriptor() ([]byte, []int) {
+func (m *TestRequest) GetShuffle() bool {
+	if m != nil {
+		return m.Shuffle
+	}
+	return false
+}
+
+func (m *TestRequest) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+// TestEvent is an event in the execution of a suite of tests
+type TestEvent struct {
+	// type is the type of event
+	Type TestEventType `protobuf:"varint,1,opt,name=type,proto3,enum=onos.test.test.TestEventType" json:"type,omitempty"`
+	// test is the name of the test to which the event applies, or empty if the event applies to the suite
+	Test string `protobuf:"bytes,2,opt,name=test,proto3" json:"test,omitempty"`
+	// output is the line of output for OUTPUT events, or the reason a suite was skipped for SKIP events
+	Output string `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	// elapsed is the time taken by the test for PASS, FAIL and SKIP events
+	Elapsed time.Duration `protobuf:"bytes,4,opt,name=elapsed,proto3,stdduration" json:"elapsed"`
+}
+
+func (m *TestEvent) Reset()         { *m = TestEvent{} }
+func (m *TestEvent) String() string { return proto.CompactTextString(m) }
+func (*TestEvent) ProtoMessage()    {}
+func (*TestEvent) Descriptor() ([]byte, []int) {
 	return fileDescriptor_84eb23d74a64bdab, []int{1}
 }
-func (m *TestResponse) XXX_Unmarshal(b []byte) error {
+func (m *TestEvent) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *TestResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *TestEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_TestResponse.Marshal(b, m, deterministic)
+		return xxx_messageInfo_TestEvent.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalToSizedBuffer(b)
@@ -107,40 +195,83 @@ func (m *TestResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error)
 		return b[:n], nil
 	}
 }
-func (m *TestResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TestResponse.Merge(m, src)
+func (m *TestEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TestEvent.Merge(m, src)
 }
-func (m *TestResponse) XXX_Size() int {
+func (m *TestEvent) XXX_Size() int {
 	return m.Size()
 }
-func (m *TestResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_TestResponse.DiscardUnknown(m)
+func (m *TestEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_TestEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TestEvent proto.InternalMessageInfo
+
+func (m *TestEvent) GetType() TestEventType {
+	if m != nil {
+		return m.Type
+	}
+	return TestEventType_START
+}
+
+func (m *TestEvent) GetTest() string {
+	if m != nil {
+		return m.Test
+	}
+	return ""
+}
+
+func (m *TestEvent) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
 }
 
-var xxx_messageInfo_TestResponse proto.InternalMessageInfo
+func (m *TestEvent) GetElapsed() time.Duration {
+	if m != nil {
+		return m.Elapsed
+	}
+	return 0
+}
 
 func init() {
+	proto.RegisterEnum("onos.test.test.TestEventType", TestEventType_name, TestEventType_value)
 	proto.RegisterType((*TestRequest)(nil), "onos.test.test.TestRequest")
-	proto.RegisterType((*TestResponse)(nil), "onos.test.test.TestResponse")
+	proto.RegisterType((*TestEvent)(nil), "onos.test.test.TestEvent")
 }
 
 func init() { proto.RegisterFile("test/test.proto", fileDescriptor_84eb23d74a64bdab) }
 
 var fileDescriptor_84eb23d74a64bdab = []byte{
-	// 194 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2f, 0x49, 0x2d, 0x2e,
-	0xd1, 0x07, 0x11, 0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x7c, 0xf9, 0x79, 0xf9, 0xc5, 0x7a,
-	0x60, 0x01, 0x10, 0x21, 0x25, 0x92, 0x9e, 0x9f, 0x9e, 0x0f, 0x96, 0xd2, 0x07, 0xb1, 0x20, 0xaa,
-	0x94, 0x2c, 0xb9, 0xb8, 0x43, 0x52, 0x8b, 0x4b, 0x82, 0x52, 0x0b, 0x4b, 0x53, 0x8b, 0x4b, 0x84,
-	0x44, 0xb8, 0x58, 0x8b, 0x4b, 0x33, 0x4b, 0x52, 0x25, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0x20,
-	0x1c, 0x90, 0x28, 0xc8, 0x88, 0x62, 0x09, 0x26, 0x05, 0x66, 0x90, 0x28, 0x98, 0xa3, 0xc4, 0xc7,
-	0xc5, 0x03, 0xd1, 0x5a, 0x5c, 0x90, 0x9f, 0x57, 0x9c, 0x6a, 0x14, 0xc6, 0xc5, 0x1b, 0x9e, 0x5f,
-	0x94, 0x9d, 0x5a, 0x14, 0x9c, 0x5a, 0x54, 0x96, 0x99, 0x9c, 0x2a, 0xe4, 0xca, 0xc5, 0x11, 0x54,
-	0x9a, 0x07, 0x52, 0x53, 0x2c, 0x24, 0xad, 0x87, 0xea, 0x1c, 0x3d, 0x24, 0x5b, 0xa5, 0x64, 0xb0,
-	0x4b, 0x42, 0xcc, 0x75, 0x92, 0x38, 0xf1, 0x48, 0x8e, 0xf1, 0xc2, 0x23, 0x39, 0xc6, 0x07, 0x8f,
-	0xe4, 0x18, 0x27, 0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58, 0x8e, 0xe1, 0xc6, 0x63, 0x39, 0x86, 0x24,
-	0x36, 0xb0, 0x1f, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xca, 0x59, 0xbc, 0x6f, 0xfc, 0x00,
-	0x00, 0x00,
+	// 425 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x52, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xf6, 0x26, 0x4e, 0x9a, 0x4c, 0x9a, 0x62, 0xad, 0x2a, 0xb4, 0x0d, 0xc2, 0xb5, 0x7a, 0x8a,
+	0x38, 0x38, 0x10, 0xce, 0x1c, 0x52, 0x85, 0x8a, 0x0a, 0x24, 0xa2, 0xf5, 0x46, 0x1c, 0x51, 0x4b,
+	0x26, 0xc1, 0x22, 0x78, 0x8d, 0x77, 0xb7, 0x52, 0xdf, 0x82, 0x23, 0xaf, 0xc0, 0x9b, 0xf4, 0xd8,
+	0x23, 0x27, 0x40, 0xc9, 0x53, 0x70, 0x43, 0xbb, 0xb6, 0x2b, 0x45, 0x02, 0x09, 0x2e, 0xa3, 0xef,
+	0x9b, 0x9f, 0x6f, 0xfc, 0x8d, 0x17, 0xee, 0x69, 0x54, 0x7a, 0x64, 0x43, 0x9c, 0x17, 0x52, 0x4b,
+	0x7a, 0x20, 0x33, 0xa9, 0x62, 0x97, 0xb0, 0x61, 0x70, 0xb8, 0x92, 0x2b, 0xe9, 0x4a, 0x23, 0x8b,
+	0xca, 0xae, 0x41, 0xb8, 0x92, 0x72, 0xb5, 0xc6, 0x91, 0x63, 0x97, 0x66, 0x39, 0x5a, 0x98, 0xe2,
+	0x42, 0xa7, 0x32, 0x2b, 0xeb, 0x27, 0xbf, 0x08, 0xf4, 0x04, 0x2a, 0xcd, 0xf1, 0x93, 0x41, 0xa5,
+	0xe9, 0x21, 0xb4, 0x94, 0x49, 0x35, 0x32, 0x12, 0x91, 0x61, 0x97, 0x97, 0xc4, 0x66, 0xed, 0x0e,
+	0xc5, 0x1a, 0x51, 0xd3, 0x66, 0x1d, 0xa1, 0x2f, 0xa0, 0xef, 0xca, 0x6f, 0x75, 0xfa, 0x11, 0xa5,
+	0xd1, 0xac, 0x19, 0x91, 0x61, 0x6f, 0x7c, 0x14, 0x97, 0x3b, 0xe3, 0x7a, 0x67, 0x3c, 0xad, 0x76,
+	0x9e, 0x76, 0x6e, 0xbe, 0x1f, 0x7b, 0x5f, 0x7e, 0x1c, 0x13, 0xbe, 0xef, 0x26, 0x45, 0x39, 0x48,
+	0xcf, 0x60, 0xdf, 0x4a, 0xde, 0x09, 0xf9, 0xff, 0x2e, 0xd4, 0xb3, 0x83, 0xb5, 0x0e, 0x83, 0x3d,
+	0xf5, 0xde, 0x2c, 0x97, 0x6b, 0x64, 0xad, 0x88, 0x0c, 0x3b, 0xbc, 0xa6, 0x94, 0x82, 0xaf, 0x10,
+	0x17, 0xac, 0x1d, 0x91, 0x61, 0x93, 0x3b, 0x7c, 0xf2, 0x95, 0x40, 0xd7, 0x7a, 0x7f, 0x7e, 0x85,
+	0x99, 0xa6, 0x4f, 0xc0, 0xd7, 0xd7, 0x79, 0x69, 0xfc, 0x60, 0xfc, 0x30, 0xde, 0x3d, 0x6f, 0x7c,
+	0xd7, 0x28, 0xae, 0x73, 0xe4, 0xae, 0xd5, 0x8a, 0xda, 0x1a, 0x6b, 0xb8, 0x5b, 0x39, 0x4c, 0xef,
+	0x43, 0x5b, 0x1a, 0x9d, 0x57, 0xd7, 0xe8, 0xf2, 0x8a, 0xd1, 0x67, 0xb0, 0x87, 0xeb, 0x8b, 0x5c,
+	0xe1, 0xe2, 0x7f, 0xdc, 0xd5, 0x33, 0x8f, 0xa6, 0xd0, 0xdf, 0xf9, 0x02, 0xda, 0x85, 0x56, 0x22,
+	0x26, 0x5c, 0x04, 0x1e, 0xed, 0x80, 0x3f, 0x9b, 0x24, 0x49, 0x40, 0x2c, 0x3a, 0x9b, 0x9c, 0xbf,
+	0x0a, 0x1a, 0x16, 0x25, 0x2f, 0xcf, 0x67, 0x41, 0x93, 0x02, 0xb4, 0x5f, 0xcf, 0xc5, 0x6c, 0x2e,
+	0x02, 0x7f, 0x3c, 0x87, 0xfe, 0x1b, 0x59, 0x7c, 0xc0, 0x22, 0xc1, 0xe2, 0x2a, 0x7d, 0x87, 0x74,
+	0x0a, 0x1d, 0x6e, 0x32, 0xe1, 0x7e, 0xe7, 0x83, 0x3f, 0x59, 0xae, 0xde, 0xc5, 0xe0, 0xe8, 0xaf,
+	0xf7, 0x78, 0x4c, 0x4e, 0xd9, 0xcd, 0x26, 0x24, 0xb7, 0x9b, 0x90, 0xfc, 0xdc, 0x84, 0xe4, 0xf3,
+	0x36, 0xf4, 0x6e, 0xb7, 0xa1, 0xf7, 0x6d, 0x1b, 0x7a, 0x97, 0x6d, 0x67, 0xee, 0xe9, 0xef, 0x01,
+	0x00, 0xf2, 0x93, 0x25, 0x38, 0xbe, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -155,7 +286,7 @@ const _ = grpc.SupportPackageIsVersion4
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type WorkerServiceClient interface {
-	RunTests(ctx context.Context, in *TestRequest, opts ...grpc.CallOption) (*TestResponse, error)
+	RunTests(ctx context.Context, in *TestRequest, opts ...grpc.CallOption) (WorkerService_RunTestsClient, error)
 }
 
 type workerServiceClient struct {
@@ -166,60 +297,87 @@ func NewWorkerServiceClient(cc *grpc.ClientConn) WorkerServiceClient {
 	return &workerServiceClient{cc}
 }
 
-func (c *workerServiceClient) RunTests(ctx context.Context, in *TestRequest, opts ...grpc.CallOption) (*TestResponse, error) {
-	out := new(TestResponse)
-	err := c.cc.Invoke(ctx, "/onos.test.test.WorkerService/RunTests", in, out, opts...)
+func (c *workerServiceClient) RunTests(ctx context.Context, in *TestRequest, opts ...grpc.CallOption) (WorkerService_RunTestsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_WorkerService_serviceDesc.Streams[0], "/onos.test.test.WorkerService/RunTests", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &workerServiceRunTestsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WorkerService_RunTestsClient interface {
+	Recv() (*TestEvent, error)
+	grpc.ClientStream
+}
+
+type workerServiceRunTestsClient struct {
+	grpc.ClientStream
+}
+
+func (x *workerServiceRunTestsClient) Recv() (*TestEvent, error) {
+	m := new(TestEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 // WorkerServiceServer is the server API for WorkerService service.
 type WorkerServiceServer interface {
-	RunTests(context.Context, *TestRequest) (*TestResponse, error)
+	RunTests(*TestRequest, WorkerService_RunTestsServer) error
 }
 
 // UnimplementedWorkerServiceServer can be embedded to have forward compatible implementations.
 type UnimplementedWorkerServiceServer struct {
 }
 
-func (*UnimplementedWorkerServiceServer) RunTests(ctx context.Context, req *TestRequest) (*TestResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunTests not implemented")
+func (*UnimplementedWorkerServiceServer) RunTests(req *TestRequest, srv WorkerService_RunTestsServer) error {
+	return status.Errorf(codes.Unimplemented, "method RunTests not implemented")
 }
 
 func RegisterWorkerServiceServer(s *grpc.Server, srv WorkerServiceServer) {
 	s.RegisterService(&_WorkerService_serviceDesc, srv)
 }
 
-func _WorkerService_RunTests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TestRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(WorkerServiceServer).RunTests(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/onos.test.test.WorkerService/RunTests",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WorkerServiceServer).RunTests(ctx, req.(*TestRequest))
+func _WorkerService_RunTests_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TestRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
 	}
-	return interceptor(ctx, in, info, handler)
+	return srv.(WorkerServiceServer).RunTests(m, &workerServiceRunTestsServer{stream})
+}
+
+type WorkerService_RunTestsServer interface {
+	Send(*TestEvent) error
+	grpc.ServerStream
+}
+
+type workerServiceRunTestsServer struct {
+	grpc.ServerStream
+}
+
+func (x *workerServiceRunTestsServer) Send(m *TestEvent) error {
+	return x.ServerStream.SendMsg(m)
 }
 
 var _WorkerService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "onos.test.test.WorkerService",
 	HandlerType: (*WorkerServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "RunTests",
-			Handler:    _WorkerService_RunTests_Handler,
+			StreamName:    "RunTests",
+			Handler:       _WorkerService_RunTests_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "test/test.proto",
 }
 
@@ -243,6 +401,37 @@ func (m *TestRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Seed != 0 {
+		i = encodeVarintTest(dAtA, i, uint64(m.Seed))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Shuffle {
+		i--
+		if m.Shuffle {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.TestTimeout, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.TestTimeout):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintTest(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x22
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.SuiteTimeout, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.SuiteTimeout):])
+	if err2 != nil {
+		return 0, err2
+	}
+	i -= n2
+	i = encodeVarintTest(dAtA, i, uint64(n2))
+	i--
+	dAtA[i] = 0x1a
 	if len(m.Tests) > 0 {
 		for iNdEx := len(m.Tests) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Tests[iNdEx])
@@ -262,7 +451,7 @@ func (m *TestRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *TestResponse) Marshal() (dAtA []byte, err error) {
+func (m *TestEvent) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -272,16 +461,43 @@ func (m *TestResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *TestResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TestEvent) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *TestResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TestEvent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	n3, err3 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Elapsed, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Elapsed):])
+	if err3 != nil {
+		return 0, err3
+	}
+	i -= n3
+	i = encodeVarintTest(dAtA, i, uint64(n3))
+	i--
+	dAtA[i] = 0x22
+	if len(m.Output) > 0 {
+		i -= len(m.Output)
+		copy(dAtA[i:], m.Output)
+		i = encodeVarintTest(dAtA, i, uint64(len(m.Output)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Test) > 0 {
+		i -= len(m.Test)
+		copy(dAtA[i:], m.Test)
+		i = encodeVarintTest(dAtA, i, uint64(len(m.Test)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Type != 0 {
+		i = encodeVarintTest(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -312,15 +528,38 @@ func (m *TestRequest) Size() (n int) {
 			n += 1 + l + sovTest(uint64(l))
 		}
 	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.SuiteTimeout)
+	n += 1 + l + sovTest(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.TestTimeout)
+	n += 1 + l + sovTest(uint64(l))
+	if m.Shuffle {
+		n += 2
+	}
+	if m.Seed != 0 {
+		n += 1 + sovTest(uint64(m.Seed))
+	}
 	return n
 }
 
-func (m *TestResponse) Size() (n int) {
+func (m *TestEvent) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
+	if m.Type != 0 {
+		n += 1 + sovTest(uint64(m.Type))
+	}
+	l = len(m.Test)
+	if l > 0 {
+		n += 1 + l + sovTest(uint64(l))
+	}
+	l = len(m.Output)
+	if l > 0 {
+		n += 1 + l + sovTest(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Elapsed)
+	n += 1 + l + sovTest(uint64(l))
 	return n
 }
 
@@ -423,6 +662,111 @@ func (m *TestRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Tests = append(m.Tests, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SuiteTimeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTest
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTest
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.SuiteTimeout, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TestTimeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTest
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTest
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.TestTimeout, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Shuffle", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Shuffle = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seed", wireType)
+			}
+			m.Seed = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seed |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTest(dAtA[iNdEx:])
@@ -447,7 +791,7 @@ func (m *TestRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TestResponse) Unmarshal(dAtA []byte) error {
+func (m *TestEvent) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -470,12 +814,128 @@ func (m *TestResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TestResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: TestEvent: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TestResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TestEvent: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= TestEventType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Test", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTest
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTest
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Test = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTest
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTest
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Output = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Elapsed", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTest
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTest
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTest
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Elapsed, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTest(dAtA[iNdEx:])
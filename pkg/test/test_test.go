@@ -0,0 +1,283 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"fmt"
+	"github.com/onosproject/helmit/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTestFilter(t *testing.T) {
+	ok, err := testFilter("TestFoo", nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = testFilter("SetupTestSuite", nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = testFilter("TestFoo", []string{"Bar", "Fo+"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = testFilter("TestFoo", []string{"^TestF$"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = testFilter("TestFoo", []string{"("})
+	assert.Error(t, err)
+}
+
+func TestFilterSuites(t *testing.T) {
+	registry.RegisterTestSuite("filter-fast", &Suite{})
+	registry.RegisterTestSuite("filter-slow", &Suite{}, "slow")
+	registry.RegisterTestSuite("filter-upgrade", &Suite{}, "slow", "upgrade")
+	suites := []string{"filter-upgrade", "filter-slow", "filter-fast"}
+
+	selected, err := filterSuites(suites, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"filter-fast", "filter-slow", "filter-upgrade"}, selected)
+
+	selected, err = filterSuites(suites, []string{"s[lt]"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"filter-fast", "filter-slow"}, selected)
+
+	selected, err = filterSuites(suites, nil, []string{"slow"}, []string{"upgrade"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"filter-slow"}, selected)
+
+	selected, err = filterSuites(suites, nil, nil, []string{"slow"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"filter-fast"}, selected)
+}
+
+type parallelSuite struct {
+	mu         sync.Mutex
+	setups     int
+	teardowns  int
+	running    int
+	maxRunning int
+	testSetups map[string]bool
+}
+
+func (s *parallelSuite) Parallelism() int {
+	return 2
+}
+
+func (s *parallelSuite) SetupTestSuite() error {
+	s.setups++
+	return nil
+}
+
+func (s *parallelSuite) TearDownTestSuite() error {
+	s.teardowns++
+	return nil
+}
+
+func (s *parallelSuite) BeforeTest(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.testSetups[name] = true
+	return nil
+}
+
+func (s *parallelSuite) run(t *testing.T) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	s.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+}
+
+func (s *parallelSuite) TestA(t *testing.T) { s.run(t) }
+func (s *parallelSuite) TestB(t *testing.T) { s.run(t) }
+func (s *parallelSuite) TestC(t *testing.T) { s.run(t) }
+func (s *parallelSuite) TestD(t *testing.T) { s.run(t) }
+
+func TestParallelSuite(t *testing.T) {
+	suite := &parallelSuite{testSetups: make(map[string]bool)}
+	t.Run("suite", func(t *testing.T) {
+		RunTests(t, suite, nil)
+		assert.Equal(t, 1, suite.setups)
+		assert.Equal(t, 1, suite.teardowns)
+	})
+	assert.Equal(t, 2, suite.maxRunning)
+	assert.Len(t, suite.testSetups, 4)
+}
+
+type timeoutSuite struct {
+	blockCh chan struct{}
+}
+
+func (s *timeoutSuite) TimeoutForTest(name string) time.Duration {
+	if name == "TestSlow" {
+		return 50 * time.Millisecond
+	}
+	return 0
+}
+
+func (s *timeoutSuite) TearDownTest() error {
+	fmt.Println("tear down test")
+	return nil
+}
+
+func (s *timeoutSuite) TearDownTestSuite() error {
+	fmt.Println("tear down suite")
+	return nil
+}
+
+func (s *timeoutSuite) TestFast(t *testing.T) {}
+
+func (s *timeoutSuite) TestSlow(t *testing.T) {
+	<-s.blockCh
+}
+
+func (s *timeoutSuite) TestSlowSuite(t *testing.T) {
+	<-s.blockCh
+}
+
+func (s *timeoutSuite) TestSuiteExpired(t *testing.T) {}
+
+// TestTimeoutSuiteHelper runs a suite with expiring timeouts when run as a subprocess of TestTimeouts
+func TestTimeoutSuiteHelper(t *testing.T) {
+	if os.Getenv("HELMIT_TIMEOUT_HELPER") == "" {
+		t.Skip("run as a subprocess of TestTimeouts")
+	}
+	runTestSuite(t, &timeoutSuite{blockCh: make(chan struct{})}, &TestRequest{
+		SuiteTimeout: 150 * time.Millisecond,
+		TestTimeout:  time.Minute,
+	})
+}
+
+func TestTimeouts(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTimeoutSuiteHelper$", "-test.v")
+	cmd.Env = append(os.Environ(), "HELMIT_TIMEOUT_HELPER=true")
+	output, err := cmd.CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(output), "--- PASS: TestTimeoutSuiteHelper/TestFast")
+	assert.Contains(t, string(output), "--- FAIL: TestTimeoutSuiteHelper/TestSlow")
+	assert.Contains(t, string(output), "--- FAIL: TestTimeoutSuiteHelper/TestSlowSuite")
+	assert.Contains(t, string(output), "test timed out after 50ms")
+	assert.Contains(t, string(output), "test timed out: suite timeout of 150ms exceeded")
+	assert.Contains(t, string(output), "goroutine ")
+	assert.Contains(t, string(output), "--- FAIL: TestTimeoutSuiteHelper/TestSuiteExpired")
+	assert.Contains(t, string(output), "suite timed out after 150ms")
+	assert.Contains(t, string(output), "tear down test")
+	assert.Contains(t, string(output), "tear down suite")
+}
+
+type hookTimeoutSuite struct {
+	setupCh    chan struct{}
+	tearDownCh chan struct{}
+}
+
+func (s *hookTimeoutSuite) SetupTestSuite() error {
+	<-s.setupCh
+	return nil
+}
+
+func (s *hookTimeoutSuite) TearDownTestSuite() error {
+	fmt.Println("tear down suite")
+	<-s.tearDownCh
+	return nil
+}
+
+func (s *hookTimeoutSuite) TestFast(t *testing.T) {}
+
+// TestHookTimeoutSuiteHelper runs a suite with hanging hooks when run as a subprocess of TestHookTimeouts
+func TestHookTimeoutSuiteHelper(t *testing.T) {
+	suite := &hookTimeoutSuite{
+		setupCh:    make(chan struct{}),
+		tearDownCh: make(chan struct{}),
+	}
+	switch os.Getenv("HELMIT_HOOK_TIMEOUT_HELPER") {
+	case "setup":
+		close(suite.tearDownCh)
+	case "teardown":
+		close(suite.setupCh)
+	default:
+		t.Skip("run as a subprocess of TestHookTimeouts")
+	}
+	runTestSuite(t, suite, &TestRequest{
+		SuiteTimeout: 100 * time.Millisecond,
+	})
+}
+
+func TestHookTimeouts(t *testing.T) {
+	run := func(hook string) string {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHookTimeoutSuiteHelper$", "-test.v")
+		cmd.Env = append(os.Environ(), "HELMIT_HOOK_TIMEOUT_HELPER="+hook)
+		output, err := cmd.CombinedOutput()
+		assert.Error(t, err)
+		return string(output)
+	}
+
+	// A hanging setup fails the suite with a dump of all goroutines, and the suite is still torn down
+	output := run("setup")
+	assert.Contains(t, output, "suite setup timed out: suite timeout of 100ms exceeded")
+	assert.Contains(t, output, "goroutine ")
+	assert.Contains(t, output, "tear down suite")
+	assert.NotContains(t, output, "TestFast")
+	assert.Contains(t, output, "--- FAIL: TestHookTimeoutSuiteHelper")
+
+	// A hanging tear down fails the suite once the suite timeout is exceeded
+	output = run("teardown")
+	assert.Contains(t, output, "--- PASS: TestHookTimeoutSuiteHelper/TestFast")
+	assert.Contains(t, output, "suite tear down timed out: suite timeout of 100ms exceeded")
+	assert.Contains(t, output, "--- FAIL: TestHookTimeoutSuiteHelper")
+}
+
+func TestGetHookTimeout(t *testing.T) {
+	assert.Equal(t, time.Duration(0), getHookTimeout(time.Time{}, time.Minute))
+	assert.Equal(t, time.Minute, getHookTimeout(time.Now().Add(-time.Second), time.Minute))
+	assert.Equal(t, time.Nanosecond, getHookTimeout(time.Now().Add(-time.Second), 0))
+	timeout := getHookTimeout(time.Now().Add(time.Second), time.Minute)
+	assert.True(t, timeout > 0 && timeout <= time.Second)
+}
+
+func TestShuffleTests(t *testing.T) {
+	newTests := func() []testing.InternalTest {
+		tests := make([]testing.InternalTest, 10)
+		for i := range tests {
+			tests[i] = testing.InternalTest{Name: fmt.Sprintf("Test%d", i)}
+		}
+		return tests
+	}
+	names := func(tests []testing.InternalTest) []string {
+		names := make([]string, len(tests))
+		for i, test := range tests {
+			names[i] = test.Name
+		}
+		return names
+	}
+
+	tests1, tests2 := newTests(), newTests()
+	shuffleTests(tests1, 42)
+	shuffleTests(tests2, 42)
+	assert.Equal(t, names(tests1), names(tests2))
+	assert.NotEqual(t, names(newTests()), names(tests1))
+	assert.ElementsMatch(t, names(newTests()), names(tests1))
+}
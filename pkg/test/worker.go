@@ -15,14 +15,19 @@
 package test
 
 import (
-	"context"
+	"flag"
 	"fmt"
 	"github.com/onosproject/helmit/pkg/helm"
+	"github.com/onosproject/helmit/pkg/job"
+	"github.com/onosproject/helmit/pkg/kubernetes"
 	"github.com/onosproject/helmit/pkg/registry"
 	"google.golang.org/grpc"
+	"io/ioutil"
 	"net"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 // newWorker returns a new test worker
@@ -35,14 +40,31 @@ func newWorker(config *Config) (*Worker, error) {
 // Worker runs a test job
 type Worker struct {
 	config *Config
+	server *grpc.Server
+	status int
 }
 
 // Run runs a benchmark
 func (w *Worker) Run() error {
+	// Apply the matrix entry's values after the release value files so they take precedence over them
+	valueFiles := w.config.ValueFiles
+	if w.config.MatrixEntry != nil {
+		dir, err := ioutil.TempDir("", "matrix")
+		if err != nil {
+			return err
+		}
+		valueFiles, err = w.config.MatrixEntry.writeValueFiles(dir, valueFiles)
+		if err != nil {
+			return err
+		}
+	}
+
 	err := helm.SetContext(&helm.Context{
-		WorkDir:    w.config.Context,
-		Values:     w.config.Values,
-		ValueFiles: w.config.ValueFiles,
+		WorkDir:      w.config.Context,
+		Values:       w.config.Values,
+		ValueFiles:   valueFiles,
+		StrictValues: w.config.StrictValues,
+		Driver:       string(w.config.HelmDriver),
 	})
 	if err != nil {
 		return err
@@ -52,38 +74,115 @@ func (w *Worker) Run() error {
 	if err != nil {
 		return err
 	}
-	server := grpc.NewServer()
-	RegisterWorkerServiceServer(server, w)
-	return server.Serve(lis)
+	// If the worker is canceled, tear down any suites that are running before exiting
+	stop := job.OnCancel(func() {
+		runCancelHooks()
+		os.Exit(job.CanceledExitCode)
+	})
+	defer stop()
+
+	if err := w.serve(lis); err != nil {
+		return err
+	}
+
+	// Exit with the status of the tests once the suite's events have been streamed to the coordinator
+	if w.status != 0 {
+		os.Exit(w.status)
+	}
+	return nil
 }
 
-// RunTests runs a suite of tests
-func (w *Worker) RunTests(ctx context.Context, request *TestRequest) (*TestResponse, error) {
-	go w.runTests(request)
-	return &TestResponse{}, nil
+// serve serves the worker's API on the given listener until a suite has been run
+func (w *Worker) serve(lis net.Listener) error {
+	w.server = grpc.NewServer()
+	RegisterWorkerServiceServer(w.server, w)
+	return w.server.Serve(lis)
 }
 
-func (w *Worker) runTests(request *TestRequest) {
+// RunTests runs a suite of tests, streaming test events back to the coordinator
+// The worker's server is stopped once the suite is complete, ending the stream and allowing the worker to exit
+// with the result of the tests.
+func (w *Worker) RunTests(request *TestRequest, stream WorkerService_RunTestsServer) error {
+	defer func() {
+		go w.server.GracefulStop()
+	}()
+
 	test := registry.GetTestSuite(request.Suite)
 	if test == nil {
-		fmt.Println(fmt.Errorf("unknown test suite %s", request.Suite))
-		os.Exit(1)
+		w.status = 1
+		return fmt.Errorf("unknown test suite %s", request.Suite)
 	}
 
+	setEventStream(stream)
+	defer setEventStream(nil)
+	restore, err := captureOutput()
+	if err != nil {
+		w.status = 1
+		return err
+	}
+
+	// The suite's result is reported once the testing package has written all of the suite's output
+	var result *TestEvent
 	tests := []testing.InternalTest{
 		{
 			Name: request.Suite,
 			F: func(t *testing.T) {
-				RunTests(t, test, request.Tests)
+				start := time.Now()
+				reportEvent(&TestEvent{
+					Type: TestEventType_START,
+				})
+				skipReason := ""
+				defer func() {
+					if skipReason != "" {
+						result = &TestEvent{
+							Type:    TestEventType_SKIP,
+							Output:  skipReason,
+							Elapsed: time.Since(start),
+						}
+					} else {
+						result = newResultEvent(t, "", time.Since(start))
+					}
+				}()
+				// Skip the suite before it's set up if the cluster can't run it
+				if requirementsTestSuite, ok := test.(RequirementsTestSuite); ok {
+					unmet, err := w.checkRequirements(requirementsTestSuite.Requirements())
+					if err != nil {
+						t.Fatalf("failed to check suite requirements: %v", err)
+					} else if len(unmet) > 0 {
+						skipReason = "requirements not met: " + strings.Join(unmet, "; ")
+						t.Skip(skipReason)
+					}
+				}
+				runTestSuite(t, test, request)
 			},
 		},
 	}
 
-	// Hack to enable verbose testing.
-	os.Args = []string{
-		os.Args[0],
-		"-test.v",
+	// Run the tests verbosely without parsing the worker's own arguments as test flags
+	os.Args = []string{os.Args[0]}
+	m := testing.MainStart(testDeps{}, tests, nil, nil, nil)
+	if err := flag.Set("test.v", "true"); err != nil {
+		restore()
+		w.status = 1
+		return err
 	}
+	w.status = m.Run()
+	restore()
 
-	testing.Main(func(_, _ string) (bool, error) { return true, nil }, tests, nil, nil)
+	if result == nil {
+		result = &TestEvent{
+			Type: TestEventType_FAIL,
+		}
+	}
+	reportEvent(result)
+	return nil
+}
+
+// checkRequirements returns the reasons the cluster does not meet the given requirements
+func (w *Worker) checkRequirements(requirements Requirements) ([]string, error) {
+	client, err := kubernetes.New()
+	if err != nil {
+		return nil, err
+	}
+	return checkRequirements(client.Clientset(), requirements)
 }
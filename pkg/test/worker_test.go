@@ -0,0 +1,118 @@
+// Copyright 2019-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"github.com/onosproject/helmit/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+type workerSuite struct {
+	Suite
+}
+
+func (s *workerSuite) TestPass(t *testing.T) {}
+
+func (s *workerSuite) TestFail(t *testing.T) {
+	if os.Getenv("HELMIT_WORKER_HELPER") == "fail" {
+		t.Fail()
+	}
+}
+
+func TestWorkerHelper(t *testing.T) {
+	if os.Getenv("HELMIT_WORKER_HELPER") == "" {
+		t.Skip("run as a subprocess of TestWorkerRunTests")
+	}
+	registry.RegisterTestSuite("worker-suite", &workerSuite{})
+	lis, err := net.Listen("tcp", os.Getenv("HELMIT_WORKER_ADDRESS"))
+	if err != nil {
+		os.Exit(2)
+	}
+	worker := &Worker{config: &Config{}}
+	if err := worker.serve(lis); err != nil {
+		os.Exit(2)
+	}
+	os.Exit(worker.status)
+}
+
+func TestWorkerRunTests(t *testing.T) {
+	run := func(result string) ([]*TestEvent, error) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		address := lis.Addr().String()
+		assert.NoError(t, lis.Close())
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestWorkerHelper$")
+		cmd.Env = append(os.Environ(), "HELMIT_WORKER_HELPER="+result, "HELMIT_WORKER_ADDRESS="+address)
+		assert.NoError(t, cmd.Start())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		// The stream ends once all of the suite's events have been sent
+		stream, err := NewWorkerServiceClient(conn).RunTests(ctx, &TestRequest{Suite: "worker-suite"})
+		assert.NoError(t, err)
+		var events []*TestEvent
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			if err != nil {
+				break
+			}
+			events = append(events, event)
+		}
+		return events, cmd.Wait()
+	}
+
+	getOutput := func(events []*TestEvent) string {
+		var output []string
+		for _, event := range events {
+			if event.Type == TestEventType_OUTPUT {
+				output = append(output, event.Output)
+			}
+		}
+		return strings.Join(output, "\n")
+	}
+
+	// The suite's result is reported after its output, and the worker exits once the stream is complete
+	events, err := run("pass")
+	assert.NoError(t, err)
+	assert.Equal(t, TestEventType_PASS, events[len(events)-1].Type)
+	assert.Equal(t, "", events[len(events)-1].Test)
+	assert.Contains(t, getOutput(events), "\n--- PASS: worker-suite (")
+
+	events, err = run("fail")
+	if assert.Error(t, err) {
+		assert.Equal(t, 1, err.(*exec.ExitError).ExitCode())
+	}
+	assert.Equal(t, TestEventType_FAIL, events[len(events)-1].Type)
+	assert.Equal(t, "", events[len(events)-1].Test)
+	assert.Contains(t, getOutput(events), "\n--- FAIL: worker-suite (")
+}
@@ -0,0 +1,196 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"github.com/onosproject/helmit/pkg/kubernetes"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Download returns a new downloader for copying files from a pod
+func Download(client kubernetes.Client) *DownloadOptions {
+	return &DownloadOptions{
+		client:    client,
+		namespace: client.Namespace(),
+	}
+}
+
+// DownloadOptions is options for copying files from a pod to a local destination
+type DownloadOptions struct {
+	client    kubernetes.Client
+	source    string
+	dest      string
+	namespace string
+	pod       string
+	container string
+}
+
+// From sets the path of the file or directory to download from the pod
+func (d *DownloadOptions) From(src string) *DownloadOptions {
+	d.source = src
+	return d
+}
+
+// To sets the local destination path
+func (d *DownloadOptions) To(dest string) *DownloadOptions {
+	d.dest = dest
+	return d
+}
+
+// On sets the pod from which to download
+func (d *DownloadOptions) On(pod string, container ...string) *DownloadOptions {
+	d.pod = pod
+	if len(container) > 0 {
+		d.container = container[0]
+	}
+	return d
+}
+
+// Do executes the download from the pod
+// If the source is a directory, its contents are written to the destination directory.
+func (d *DownloadOptions) Do() error {
+	if d.source == "" || d.pod == "" {
+		return errors.New("source and pod cannot be empty")
+	}
+
+	pod, err := d.client.Clientset().CoreV1().Pods(d.namespace).Get(d.pod, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	containerName := d.container
+	if len(containerName) == 0 {
+		if len(pod.Spec.Containers) > 1 {
+			return errors.New("source container is ambiguous")
+		}
+		containerName = pod.Spec.Containers[0].Name
+	}
+
+	if d.dest == "" {
+		d.dest = path.Base(d.source)
+	}
+
+	source := path.Clean(d.source)
+	cmd := []string{"tar", "-cf", "-", "-C", path.Dir(source), path.Base(source)}
+	req := d.client.Clientset().CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Name(d.pod).
+		Namespace(d.namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   cmd,
+			Stdin:     false,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       false,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(d.client.Config(), "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	return download(func(writer io.Writer) error {
+		return exec.Stream(remotecommand.StreamOptions{
+			Stdout: writer,
+			Stderr: os.Stderr,
+			Tty:    false,
+		})
+	}, path.Base(source), d.dest)
+}
+
+// download extracts the tar archive written by the given stream function to the destination path
+// The stream is read to the end so that trailing archive padding never blocks the writer.
+func download(stream func(io.Writer) error, prefix, dest string) error {
+	reader, writer := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		err := stream(writer)
+		writer.CloseWithError(err)
+		errCh <- err
+	}()
+
+	if err := untar(reader, prefix, dest); err != nil {
+		reader.CloseWithError(err)
+		<-errCh
+		return err
+	}
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		reader.CloseWithError(err)
+		<-errCh
+		return err
+	}
+	return <-errCh
+}
+
+// untar extracts the given tar stream, writing the entries under the given prefix to the destination path
+func untar(reader io.Reader, prefix, dest string) error {
+	dest = filepath.Clean(dest)
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		if target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator)) {
+			return fmt.Errorf("invalid file path %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
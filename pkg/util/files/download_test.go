@@ -0,0 +1,111 @@
+// Copyright 2020-present Open Networking Foundation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package files
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUntar(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "artifacts/", Typeflag: tar.TypeDir, Mode: 0755}))
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "artifacts/logs/", Typeflag: tar.TypeDir, Mode: 0755}))
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "artifacts/logs/test.log", Typeflag: tar.TypeReg, Mode: 0644, Size: 5}))
+	_, err := writer.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "other/file", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NoError(t, writer.Close())
+
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dest := filepath.Join(dir, "suite")
+	assert.NoError(t, untar(buf, "artifacts", dest))
+	bytes, err := ioutil.ReadFile(filepath.Join(dest, "logs", "test.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(bytes))
+	_, err = os.Stat(filepath.Join(dest, "file"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUntarInvalidPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "artifacts/../../escape", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NoError(t, writer.Close())
+
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, untar(buf, "artifacts", filepath.Join(dir, "suite")))
+	_, err = os.Stat(filepath.Join(dir, "escape"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadPaddedArchive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "artifacts/test.log", Typeflag: tar.TypeReg, Mode: 0644, Size: 5}))
+	_, err := writer.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	// tar pads archives to a 10KiB record, which the tar reader never consumes
+	buf.Write(make([]byte, 10240-buf.Len()%10240))
+
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- download(func(w io.Writer) error {
+			_, err := io.Copy(w, bytes.NewReader(buf.Bytes()))
+			return err
+		}, "artifacts", dir)
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("download did not complete")
+	}
+	bytes, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(bytes))
+}
+
+func TestDownloadStreamError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helmit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = download(func(w io.Writer) error {
+		return errors.New("exec failed")
+	}, "artifacts", dir)
+	assert.EqualError(t, err, "exec failed")
+}